@@ -1,6 +1,8 @@
 package v1alpha1
 
 import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -12,34 +14,344 @@ type NodeTarget struct {
 	// +optional
 	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
 
-	// MaxPodsPerNode is the maximum number of pods allowed on each node matching this selector.
-	// Pods are evicted only when this limit is exceeded.
+	// Selector selects nodes with label expressions (In, NotIn, Exists, DoesNotExist).
+	// Combined with NodeSelector when both are set.
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+
+	// Priority decides between targets matching the same node; the highest priority wins.
+	// Ties go to the most specific target (the one with the most label requirements), then to the first listed.
+	// +optional
+	Priority int32 `json:"priority,omitempty"`
+
+	// MaxPodsPerNode is the maximum number of pods for each node matching this selector.
+	// How it is enforced depends on the request's TargetMode: Proportional uses it as the node's
+	// weight and may evict below it, HardCap evicts only above it, and Both evicts down to the
+	// proportional share but never lets a node exceed it.
+	// Required unless Capacity is set, in which case the smaller of the two is the maximum.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxPodsPerNode int32 `json:"maxPodsPerNode,omitempty"`
+
+	// Capacity derives the maximum pod count from each node's allocatable resources,
+	// so new instance types need no spec changes.
+	// +optional
+	Capacity *NodeCapacity `json:"capacity,omitempty"`
+}
+
+// NodeCapacity derives a node's maximum pod count from its status.allocatable
+type NodeCapacity struct {
+	// PodRequests is the resource profile of one pod (e.g., cpu, memory, nvidia.com/gpu).
+	// A node fits allocatable/request pods for each listed resource, and the smallest count wins.
+	// A node without a listed resource fits no pods; a profile without requests leaves only the
+	// node's pod limit.
+	// +optional
+	PodRequests corev1.ResourceList `json:"podRequests,omitempty"`
+
+	// FromManagedPods uses the largest requests of the managed pods as the profile instead of PodRequests.
+	// +optional
+	FromManagedPods bool `json:"fromManagedPods,omitempty"`
+}
+
+// TargetMode controls how a node's configured maximum turns into its eviction target
+// +kubebuilder:validation:Enum=Proportional;HardCap;Both
+type TargetMode string
+
+const (
+	// TargetModeProportional targets each node's capacity-proportional share of the managed pods
+	TargetModeProportional TargetMode = "Proportional"
+	// TargetModeHardCap evicts only from nodes above their configured maximum
+	TargetModeHardCap TargetMode = "HardCap"
+	// TargetModeBoth targets the proportional share but never more than the configured maximum
+	TargetModeBoth TargetMode = "Both"
+)
+
+// BalanceStrategy selects what the rebalancer balances across nodes
+// +kubebuilder:validation:Enum=PodCount;Load
+type BalanceStrategy string
+
+const (
+	// BalanceStrategyPodCount balances the number of managed pods per node
+	BalanceStrategyPodCount BalanceStrategy = "PodCount"
+	// BalanceStrategyLoad balances measured resource utilisation, evicting from hot nodes
+	BalanceStrategyLoad BalanceStrategy = "Load"
+)
+
+// RunMode selects whether a request keeps running
+// +kubebuilder:validation:Enum=Continuous;Once
+type RunMode string
+
+const (
+	// RunModeContinuous runs every interval for as long as the request exists
+	RunModeContinuous RunMode = "Continuous"
+	// RunModeOnce runs until a run finds nothing to evict, then completes
+	RunModeOnce RunMode = "Once"
+)
+
+// LoadSource selects where load measurements come from
+// +kubebuilder:validation:Enum=Metrics;Prometheus
+type LoadSource string
+
+const (
+	// LoadSourceMetrics reads resource usage from the metrics.k8s.io API
+	LoadSourceMetrics LoadSource = "Metrics"
+	// LoadSourcePrometheus runs PromQL queries against the manager's Prometheus
+	LoadSourcePrometheus LoadSource = "Prometheus"
+)
+
+// PrometheusLoad configures the PromQL queries used as the load signal.
+// Each query must return an instant vector.
+type PrometheusLoad struct {
+	// PodQuery returns the load of each pod, keyed by its namespace and pod labels.
+	// +kubebuilder:validation:MinLength=1
+	PodQuery string `json:"podQuery"`
+
+	// NodeQuery returns the load of each node, keyed by its node label.
+	// Defaults to the sum of the pod loads on each node.
+	// +optional
+	NodeQuery string `json:"nodeQuery,omitempty"`
+}
+
+// LoadBalancing configures load-aware balancing
+type LoadBalancing struct {
+	// Source selects where load is measured.
+	// +kubebuilder:default=Metrics
+	// +optional
+	Source LoadSource `json:"source,omitempty"`
+
+	// Prometheus configures the queries for the Prometheus source.
+	// +optional
+	Prometheus *PrometheusLoad `json:"prometheus,omitempty"`
+
+	// Resource is the resource whose utilisation is balanced by the Metrics source.
+	// +kubebuilder:validation:Enum=cpu;memory
+	// +kubebuilder:default=cpu
+	// +optional
+	Resource corev1.ResourceName `json:"resource,omitempty"`
+
+	// ThresholdPercent is how many percentage points above the average utilisation a node may run before it is hot.
+	// For the Prometheus source, which has no node capacity, it is the percentage above the average load.
 	// +kubebuilder:validation:Minimum=1
-	MaxPodsPerNode int32 `json:"maxPodsPerNode"`
+	// +kubebuilder:default=10
+	// +optional
+	ThresholdPercent int32 `json:"thresholdPercent,omitempty"`
+
+	// Samples is the number of recent measurements averaged to smooth out spikes.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=10
+	// +kubebuilder:default=3
+	// +optional
+	Samples int32 `json:"samples,omitempty"`
+}
+
+// UnmatchedNodeMode controls how nodes that match no NodeTarget are handled
+// +kubebuilder:validation:Enum=Exclude;Average;Fixed
+type UnmatchedNodeMode string
+
+const (
+	// UnmatchedNodeExclude leaves unmatched nodes out of balancing entirely
+	UnmatchedNodeExclude UnmatchedNodeMode = "Exclude"
+	// UnmatchedNodeAverage gives unmatched nodes the average maximum of the matched nodes
+	UnmatchedNodeAverage UnmatchedNodeMode = "Average"
+	// UnmatchedNodeFixed gives unmatched nodes a fixed maximum, 1000 unless set
+	UnmatchedNodeFixed UnmatchedNodeMode = "Fixed"
+)
+
+// UnmatchedNodePolicy sets the maximum pod count for nodes that match no NodeTarget
+type UnmatchedNodePolicy struct {
+	// Mode selects how unmatched nodes are handled.
+	// +kubebuilder:default=Fixed
+	Mode UnmatchedNodeMode `json:"mode,omitempty"`
+
+	// MaxPodsPerNode is the maximum for unmatched nodes when Mode is Fixed. Defaults to 1000,
+	// high enough that pods may land on new node types without being evicted at once.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxPodsPerNode int32 `json:"maxPodsPerNode,omitempty"`
+}
+
+// CapacityAccounting adjusts node capacity for load the rebalancer does not manage
+type CapacityAccounting struct {
+	// UnmanagedPods subtracts pods the rebalancer does not manage from each node's capacity,
+	// so a node full of unrelated workloads is not treated as empty.
+	// +optional
+	UnmanagedPods bool `json:"unmanagedPods,omitempty"`
+
+	// NodePressure caps the capacity of nodes with MemoryPressure, DiskPressure or PIDPressure
+	// at their current managed pod count, so they never receive pods.
+	// +optional
+	NodePressure bool `json:"nodePressure,omitempty"`
+}
+
+// LocalStoragePolicy controls which pods using node-local storage may be evicted.
+// Data in node-local volumes is lost when a pod is evicted, so everything is disallowed by default.
+type LocalStoragePolicy struct {
+	// AllowMemoryEmptyDir allows evicting pods with memory-backed (tmpfs) emptyDir volumes.
+	// +optional
+	AllowMemoryEmptyDir bool `json:"allowMemoryEmptyDir,omitempty"`
+
+	// MaxEmptyDirSize allows evicting pods whose disk-backed emptyDir volumes all set a sizeLimit at or below this size.
+	// Disk-backed emptyDir volumes without a sizeLimit are never allowed by this setting.
+	// +optional
+	MaxEmptyDirSize *resource.Quantity `json:"maxEmptyDirSize,omitempty"`
+
+	// AllowHostPath allows evicting pods with hostPath volumes.
+	// +optional
+	AllowHostPath bool `json:"allowHostPath,omitempty"`
+
+	// AllowLocalPersistentVolumes allows evicting pods whose claims are bound to local or hostPath PersistentVolumes.
+	// +optional
+	AllowLocalPersistentVolumes bool `json:"allowLocalPersistentVolumes,omitempty"`
+}
+
+// WorkloadAction controls how pods of a workload kind are handled
+// +kubebuilder:validation:Enum=Evict;Skip;Serial
+type WorkloadAction string
+
+const (
+	// WorkloadActionEvict allows evicting any number of the workload's pods
+	WorkloadActionEvict WorkloadAction = "Evict"
+	// WorkloadActionSkip never evicts the workload's pods
+	WorkloadActionSkip WorkloadAction = "Skip"
+	// WorkloadActionSerial evicts at most one of the workload's pods per run, and only when all its replicas are Ready
+	WorkloadActionSerial WorkloadAction = "Serial"
+)
+
+// WorkloadPolicy sets the action for pods whose top-level owner is of the given kind
+type WorkloadPolicy struct {
+	// Kind of the top-level owner, resolved through ReplicaSet to Deployment and Job to CronJob
+	// (e.g., Deployment, StatefulSet, CronJob). Use "None" for pods without a controller.
+	Kind string `json:"kind"`
+
+	// Action to take for pods of this kind.
+	Action WorkloadAction `json:"action"`
 }
 
 // RebalanceRequestSpec defines the desired state of RebalanceRequest
 type RebalanceRequestSpec struct {
 	// Selector specifies which pods to consider for rebalancing.
-	// Only pods matching this selector AND having the label kore.boring.io/rebalance=true will be considered.
+	// Only pods matching this selector AND opted in (see OptInLabel) will be considered.
 	// +optional
 	Selector *metav1.LabelSelector `json:"selector,omitempty"`
 
+	// OptInLabel is the label pods must carry with value "true" to be considered for rebalancing.
+	// Defaults to the manager's --opt-in-label flag (kore.boring.io/rebalance).
+	// +optional
+	OptInLabel string `json:"optInLabel,omitempty"`
+
 	// Namespaces to target. Empty means all namespaces.
+	// When NamespaceSelector is also set, only listed namespaces matching the selector are used.
 	// +optional
 	Namespaces []string `json:"namespaces,omitempty"`
 
+	// NamespaceSelector selects namespaces by label (e.g., rebalance=enabled).
+	// +optional
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+
+	// ExcludedNamespaces are never considered for rebalancing.
+	// Defaults to kube-system, kube-public and kube-node-lease when unset. Set to an empty list to exclude nothing.
+	// +optional
+	ExcludedNamespaces []string `json:"excludedNamespaces,omitempty"`
+
+	// NodeSelector limits balancing to nodes matching this selector. Empty means all nodes.
+	// +optional
+	NodeSelector *metav1.LabelSelector `json:"nodeSelector,omitempty"`
+
+	// ExcludeNodeSelector excludes nodes matching this selector from balancing (e.g., control-plane nodes).
+	// Nodes with NoSchedule or NoExecute taints that no managed pod tolerates are always excluded.
+	// +optional
+	ExcludeNodeSelector *metav1.LabelSelector `json:"excludeNodeSelector,omitempty"`
+
 	// NodeTargets defines per-hardware-type maximum pod counts.
 	// Pods are evicted from nodes exceeding their maximum to allow redistribution.
 	// If not specified, pods are balanced evenly across all nodes.
 	// +optional
 	NodeTargets []NodeTarget `json:"nodeTargets,omitempty"`
 
+	// TargetMode controls how NodeTargets maximums are enforced.
+	// Proportional spreads pods by capacity share, HardCap evicts only above the maximum,
+	// and Both uses the proportional share capped at the maximum.
+	// +kubebuilder:default=Proportional
+	// +optional
+	TargetMode TargetMode `json:"targetMode,omitempty"`
+
+	// Strategy selects what is balanced. Load reads usage from the metrics.k8s.io API and
+	// falls back to PodCount when metrics are unavailable.
+	// +kubebuilder:default=PodCount
+	// +optional
+	Strategy BalanceStrategy `json:"strategy,omitempty"`
+
+	// Load configures the Load strategy.
+	// +optional
+	Load *LoadBalancing `json:"load,omitempty"`
+
+	// CapacityAccounting subtracts unmanaged pods and node pressure from effective capacity.
+	// Without NodeTargets, enabling it balances by each node's allocatable pod count instead of an even spread.
+	// +optional
+	CapacityAccounting *CapacityAccounting `json:"capacityAccounting,omitempty"`
+
+	// UnmatchedNodes controls nodes that match none of the NodeTargets.
+	// Defaults to a fixed maximum of 1000 pods.
+	// +optional
+	UnmatchedNodes *UnmatchedNodePolicy `json:"unmatchedNodes,omitempty"`
+
+	// LocalStoragePolicy controls eviction of pods using node-local storage.
+	// Pods labelled kore.boring.io/allow-local-storage-eviction=true are always allowed.
+	// +optional
+	LocalStoragePolicy *LocalStoragePolicy `json:"localStoragePolicy,omitempty"`
+
+	// WorkloadPolicies override how pods are handled based on their top-level owner kind.
+	// By default pods without a controller, Jobs and CronJobs are skipped, StatefulSets are Serial,
+	// and everything else is evicted. DaemonSet pods are always skipped.
+	// +optional
+	WorkloadPolicies []WorkloadPolicy `json:"workloadPolicies,omitempty"`
+
+	// Mode selects whether the request rebalances continuously, or once until a run finds nothing to evict.
+	// +kubebuilder:default=Continuous
+	// +optional
+	Mode RunMode `json:"mode,omitempty"`
+
+	// MaxAttempts is the number of runs that evict or try to evict pods a Once request may take
+	// to balance before it fails.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default=5
+	// +optional
+	MaxAttempts int32 `json:"maxAttempts,omitempty"`
+
+	// TTLSecondsAfterFinished deletes a Once request this long after it completes or fails.
+	// Finished requests are kept when unset.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	TTLSecondsAfterFinished *int32 `json:"ttlSecondsAfterFinished,omitempty"`
+
 	// IntervalSeconds sets how often the rebalancer checks and maintains balance.
 	// +kubebuilder:validation:Minimum=30
 	// +kubebuilder:default=60
 	IntervalSeconds int32 `json:"intervalSeconds,omitempty"`
 
+	// MinRunGapSeconds is the minimum time between runs triggered early by cluster events,
+	// such as a node being added or becoming Ready.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:default=30
+	// +optional
+	MinRunGapSeconds int32 `json:"minRunGapSeconds,omitempty"`
+
+	// EvictionHistory limits how often the same workload is moved. Evictions are always recorded
+	// in status.recentEvictions, and a replacement is never evicted from the node its workload just
+	// left within the window; the per-workload limit only applies when this is set.
+	// +optional
+	EvictionHistory *EvictionHistoryPolicy `json:"evictionHistory,omitempty"`
+
+	// CircuitBreaker halts evictions while the cluster looks unhealthy.
+	// Enabled with the default thresholds when unset, including on requests created before it existed;
+	// set disabled to keep evicting regardless. Thresholds left at 0 take their defaults.
+	// +optional
+	CircuitBreaker *CircuitBreakerPolicy `json:"circuitBreaker,omitempty"`
+
+	// Report writes a JSON report of every run to the ConfigMap named in status.reportConfigMap.
+	// +optional
+	Report *RunReportPolicy `json:"report,omitempty"`
+
 	// BatchSize is the number of pods to evict per batch.
 	// +kubebuilder:validation:Minimum=1
 	// +kubebuilder:default=5
@@ -56,18 +368,150 @@ type RebalanceRequestSpec struct {
 	// +kubebuilder:default=false
 	// +optional
 	DryRun bool `json:"dryRun,omitempty"`
+
+	// Paused stops scheduled and event-triggered runs until it is cleared.
+	// +optional
+	Paused bool `json:"paused,omitempty"`
 }
 
 // RebalancePhase represents the current phase of a rebalance operation
-// +kubebuilder:validation:Enum=Pending;Active;Failed
+// +kubebuilder:validation:Enum=Pending;Active;Completed;Failed
 type RebalancePhase string
 
 const (
-	RebalancePhasePending RebalancePhase = "Pending"
-	RebalancePhaseActive  RebalancePhase = "Active"
-	RebalancePhaseFailed  RebalancePhase = "Failed"
+	RebalancePhasePending   RebalancePhase = "Pending"
+	RebalancePhaseActive    RebalancePhase = "Active"
+	RebalancePhaseCompleted RebalancePhase = "Completed"
+	RebalancePhaseFailed    RebalancePhase = "Failed"
 )
 
+// ConditionTypeDegraded is True while the circuit breaker halts evictions
+const ConditionTypeDegraded = "Degraded"
+
+// EvictionHistoryPolicy limits repeated evictions of the same workload
+type EvictionHistoryPolicy struct {
+	// WindowSeconds is how long evictions are remembered.
+	// +kubebuilder:validation:Minimum=60
+	// +kubebuilder:default=600
+	// +optional
+	WindowSeconds int32 `json:"windowSeconds,omitempty"`
+
+	// MaxPerWorkload is the maximum number of pods of one workload evicted within the window.
+	// 0 means no limit.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxPerWorkload int32 `json:"maxPerWorkload,omitempty"`
+}
+
+// RunReportPolicy configures the per-run reports
+type RunReportPolicy struct {
+	// HistoryLimit is the number of run reports kept.
+	// Older reports are also dropped to keep the ConfigMap under 900KiB.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	// +kubebuilder:default=10
+	// +optional
+	HistoryLimit int32 `json:"historyLimit,omitempty"`
+}
+
+// CircuitBreakerPolicy sets the guards that halt evictions. A guard trips when its count exceeds the maximum.
+type CircuitBreakerPolicy struct {
+	// Disabled turns the circuit breaker off.
+	// +optional
+	Disabled bool `json:"disabled,omitempty"`
+
+	// MaxPendingPods is the number of Pending managed pods tolerated.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:default=10
+	// +optional
+	MaxPendingPods int32 `json:"maxPendingPods,omitempty"`
+
+	// MaxEvictionFailures is the number of failed evictions tolerated in a run.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:default=3
+	// +optional
+	MaxEvictionFailures int32 `json:"maxEvictionFailures,omitempty"`
+
+	// MaxUnschedulableReplacements is the number of unschedulable managed pods tolerated
+	// for workloads evicted within the eviction history window.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxUnschedulableReplacements int32 `json:"maxUnschedulableReplacements,omitempty"`
+
+	// MaxNotReadyNodesPercent is the percentage of NotReady nodes tolerated.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	// +kubebuilder:default=20
+	// +optional
+	MaxNotReadyNodesPercent int32 `json:"maxNotReadyNodesPercent,omitempty"`
+
+	// CooldownSeconds is how long evictions stay halted after a guard trips.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:default=300
+	// +optional
+	CooldownSeconds int32 `json:"cooldownSeconds,omitempty"`
+}
+
+// EvictionRecord records one eviction
+type EvictionRecord struct {
+	// Pod is the evicted pod as namespace/name.
+	Pod string `json:"pod"`
+
+	// Workload is the pod's top-level owner as kind/namespace/name.
+	Workload string `json:"workload"`
+
+	// Node is the node the pod was evicted from.
+	Node string `json:"node"`
+
+	// Time is when the pod was evicted.
+	Time metav1.Time `json:"time"`
+}
+
+// SkippedPodsSummary counts managed pods that were not considered for eviction for one reason
+type SkippedPodsSummary struct {
+	// Reason is why the pods were skipped (e.g., HostPath, MemoryEmptyDir).
+	Reason string `json:"reason"`
+
+	// Count is the number of pods skipped for this reason.
+	Count int32 `json:"count"`
+
+	// Examples lists a few of the skipped pods as namespace/name.
+	// +optional
+	Examples []string `json:"examples,omitempty"`
+}
+
+// NodeDistribution records a node's managed pod count and target from the last run
+type NodeDistribution struct {
+	// Name of the node.
+	Name string `json:"name"`
+
+	// PodCount is the number of managed pods on the node.
+	PodCount int32 `json:"podCount"`
+
+	// MaxPods is the configured or derived maximum for the node, if any.
+	// +optional
+	MaxPods int32 `json:"maxPods,omitempty"`
+
+	// UnmanagedPods is the number of other pods subtracted from the node's capacity.
+	// +optional
+	UnmanagedPods int32 `json:"unmanagedPods,omitempty"`
+
+	// UnderPressure is true if the node reported memory, disk or PID pressure.
+	// +optional
+	UnderPressure bool `json:"underPressure,omitempty"`
+
+	// UtilizationPercent is the node's smoothed utilisation when balancing by load,
+	// or its load relative to the average for the Prometheus source.
+	// +optional
+	UtilizationPercent int32 `json:"utilizationPercent,omitempty"`
+
+	// Target is the pod count above which pods are evicted from the node.
+	Target int32 `json:"target"`
+
+	// TargetSource is how the target was produced: Average, Proportional, HardCap or Load.
+	TargetSource string `json:"targetSource"`
+}
+
 // RebalanceRequestStatus defines the observed state of RebalanceRequest
 type RebalanceRequestStatus struct {
 	// Phase represents the current phase of the rebalance operation.
@@ -83,6 +527,10 @@ type RebalanceRequestStatus struct {
 	// RunCount tracks how many times the rebalancer has run.
 	RunCount int32 `json:"runCount,omitempty"`
 
+	// Attempts counts the runs of a Once request that evicted or tried to evict pods.
+	// +optional
+	Attempts int32 `json:"attempts,omitempty"`
+
 	// StartTime is when the rebalancer started.
 	// +optional
 	StartTime *metav1.Time `json:"startTime,omitempty"`
@@ -95,6 +543,34 @@ type RebalanceRequestStatus struct {
 	// +optional
 	NextRunTime *metav1.Time `json:"nextRunTime,omitempty"`
 
+	// LastRunNow is the last kore.boring.io/run-now annotation value that triggered a run.
+	// +optional
+	LastRunNow string `json:"lastRunNow,omitempty"`
+
+	// Nodes is the per-node distribution and targets from the last run.
+	// +optional
+	Nodes []NodeDistribution `json:"nodes,omitempty"`
+
+	// SkippedPods summarizes why managed pods were not considered in the last run.
+	// +optional
+	SkippedPods []SkippedPodsSummary `json:"skippedPods,omitempty"`
+
+	// CompletionTime is when a Once request completed or failed.
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+
+	// CircuitOpenUntil is when evictions resume after the circuit breaker tripped.
+	// +optional
+	CircuitOpenUntil *metav1.Time `json:"circuitOpenUntil,omitempty"`
+
+	// RecentEvictions lists the evictions within the eviction history window, oldest first.
+	// +optional
+	RecentEvictions []EvictionRecord `json:"recentEvictions,omitempty"`
+
+	// ReportConfigMap is the ConfigMap holding the run reports.
+	// +optional
+	ReportConfigMap string `json:"reportConfigMap,omitempty"`
+
 	// Message provides additional information about the current status.
 	// +optional
 	Message string `json:"message,omitempty"`
@@ -5,10 +5,149 @@
 package v1alpha1
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CapacityAccounting) DeepCopyInto(out *CapacityAccounting) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CapacityAccounting.
+func (in *CapacityAccounting) DeepCopy() *CapacityAccounting {
+	if in == nil {
+		return nil
+	}
+	out := new(CapacityAccounting)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CircuitBreakerPolicy) DeepCopyInto(out *CircuitBreakerPolicy) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CircuitBreakerPolicy.
+func (in *CircuitBreakerPolicy) DeepCopy() *CircuitBreakerPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(CircuitBreakerPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EvictionHistoryPolicy) DeepCopyInto(out *EvictionHistoryPolicy) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EvictionHistoryPolicy.
+func (in *EvictionHistoryPolicy) DeepCopy() *EvictionHistoryPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(EvictionHistoryPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EvictionRecord) DeepCopyInto(out *EvictionRecord) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EvictionRecord.
+func (in *EvictionRecord) DeepCopy() *EvictionRecord {
+	if in == nil {
+		return nil
+	}
+	out := new(EvictionRecord)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LoadBalancing) DeepCopyInto(out *LoadBalancing) {
+	*out = *in
+	if in.Prometheus != nil {
+		in, out := &in.Prometheus, &out.Prometheus
+		*out = new(PrometheusLoad)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoadBalancing.
+func (in *LoadBalancing) DeepCopy() *LoadBalancing {
+	if in == nil {
+		return nil
+	}
+	out := new(LoadBalancing)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LocalStoragePolicy) DeepCopyInto(out *LocalStoragePolicy) {
+	*out = *in
+	if in.MaxEmptyDirSize != nil {
+		in, out := &in.MaxEmptyDirSize, &out.MaxEmptyDirSize
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalStoragePolicy.
+func (in *LocalStoragePolicy) DeepCopy() *LocalStoragePolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(LocalStoragePolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeCapacity) DeepCopyInto(out *NodeCapacity) {
+	*out = *in
+	if in.PodRequests != nil {
+		in, out := &in.PodRequests, &out.PodRequests
+		*out = make(corev1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeCapacity.
+func (in *NodeCapacity) DeepCopy() *NodeCapacity {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeCapacity)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeDistribution) DeepCopyInto(out *NodeDistribution) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeDistribution.
+func (in *NodeDistribution) DeepCopy() *NodeDistribution {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeDistribution)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NodeTarget) DeepCopyInto(out *NodeTarget) {
 	*out = *in
@@ -19,6 +158,16 @@ func (in *NodeTarget) DeepCopyInto(out *NodeTarget) {
 			(*out)[key] = val
 		}
 	}
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Capacity != nil {
+		in, out := &in.Capacity, &out.Capacity
+		*out = new(NodeCapacity)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeTarget.
@@ -31,6 +180,21 @@ func (in *NodeTarget) DeepCopy() *NodeTarget {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PrometheusLoad) DeepCopyInto(out *PrometheusLoad) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PrometheusLoad.
+func (in *PrometheusLoad) DeepCopy() *PrometheusLoad {
+	if in == nil {
+		return nil
+	}
+	out := new(PrometheusLoad)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RebalanceRequest) DeepCopyInto(out *RebalanceRequest) {
 	*out = *in
@@ -103,6 +267,26 @@ func (in *RebalanceRequestSpec) DeepCopyInto(out *RebalanceRequestSpec) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.NamespaceSelector != nil {
+		in, out := &in.NamespaceSelector, &out.NamespaceSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ExcludedNamespaces != nil {
+		in, out := &in.ExcludedNamespaces, &out.ExcludedNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ExcludeNodeSelector != nil {
+		in, out := &in.ExcludeNodeSelector, &out.ExcludeNodeSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.NodeTargets != nil {
 		in, out := &in.NodeTargets, &out.NodeTargets
 		*out = make([]NodeTarget, len(*in))
@@ -110,6 +294,51 @@ func (in *RebalanceRequestSpec) DeepCopyInto(out *RebalanceRequestSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Load != nil {
+		in, out := &in.Load, &out.Load
+		*out = new(LoadBalancing)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CapacityAccounting != nil {
+		in, out := &in.CapacityAccounting, &out.CapacityAccounting
+		*out = new(CapacityAccounting)
+		**out = **in
+	}
+	if in.UnmatchedNodes != nil {
+		in, out := &in.UnmatchedNodes, &out.UnmatchedNodes
+		*out = new(UnmatchedNodePolicy)
+		**out = **in
+	}
+	if in.LocalStoragePolicy != nil {
+		in, out := &in.LocalStoragePolicy, &out.LocalStoragePolicy
+		*out = new(LocalStoragePolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.WorkloadPolicies != nil {
+		in, out := &in.WorkloadPolicies, &out.WorkloadPolicies
+		*out = make([]WorkloadPolicy, len(*in))
+		copy(*out, *in)
+	}
+	if in.TTLSecondsAfterFinished != nil {
+		in, out := &in.TTLSecondsAfterFinished, &out.TTLSecondsAfterFinished
+		*out = new(int32)
+		**out = **in
+	}
+	if in.EvictionHistory != nil {
+		in, out := &in.EvictionHistory, &out.EvictionHistory
+		*out = new(EvictionHistoryPolicy)
+		**out = **in
+	}
+	if in.CircuitBreaker != nil {
+		in, out := &in.CircuitBreaker, &out.CircuitBreaker
+		*out = new(CircuitBreakerPolicy)
+		**out = **in
+	}
+	if in.Report != nil {
+		in, out := &in.Report, &out.Report
+		*out = new(RunReportPolicy)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RebalanceRequestSpec.
@@ -137,6 +366,33 @@ func (in *RebalanceRequestStatus) DeepCopyInto(out *RebalanceRequestStatus) {
 		in, out := &in.NextRunTime, &out.NextRunTime
 		*out = (*in).DeepCopy()
 	}
+	if in.Nodes != nil {
+		in, out := &in.Nodes, &out.Nodes
+		*out = make([]NodeDistribution, len(*in))
+		copy(*out, *in)
+	}
+	if in.SkippedPods != nil {
+		in, out := &in.SkippedPods, &out.SkippedPods
+		*out = make([]SkippedPodsSummary, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CircuitOpenUntil != nil {
+		in, out := &in.CircuitOpenUntil, &out.CircuitOpenUntil
+		*out = (*in).DeepCopy()
+	}
+	if in.RecentEvictions != nil {
+		in, out := &in.RecentEvictions, &out.RecentEvictions
+		*out = make([]EvictionRecord, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
 		*out = make([]v1.Condition, len(*in))
@@ -155,3 +411,68 @@ func (in *RebalanceRequestStatus) DeepCopy() *RebalanceRequestStatus {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RunReportPolicy) DeepCopyInto(out *RunReportPolicy) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RunReportPolicy.
+func (in *RunReportPolicy) DeepCopy() *RunReportPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(RunReportPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SkippedPodsSummary) DeepCopyInto(out *SkippedPodsSummary) {
+	*out = *in
+	if in.Examples != nil {
+		in, out := &in.Examples, &out.Examples
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SkippedPodsSummary.
+func (in *SkippedPodsSummary) DeepCopy() *SkippedPodsSummary {
+	if in == nil {
+		return nil
+	}
+	out := new(SkippedPodsSummary)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UnmatchedNodePolicy) DeepCopyInto(out *UnmatchedNodePolicy) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UnmatchedNodePolicy.
+func (in *UnmatchedNodePolicy) DeepCopy() *UnmatchedNodePolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(UnmatchedNodePolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WorkloadPolicy) DeepCopyInto(out *WorkloadPolicy) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkloadPolicy.
+func (in *WorkloadPolicy) DeepCopy() *WorkloadPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(WorkloadPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
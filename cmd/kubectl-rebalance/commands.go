@@ -0,0 +1,213 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	korev1alpha1 "github.com/cxfcxf/pod-rebalancer/api/v1alpha1"
+	"github.com/cxfcxf/pod-rebalancer/internal/rebalancer"
+	"github.com/cxfcxf/pod-rebalancer/internal/report"
+)
+
+// runStatus prints the request's state and the node table recorded by its last run
+func runStatus(_ context.Context, p *plugin, req *korev1alpha1.RebalanceRequest) error {
+	return report.Print(p.out, p.output, req.Status, func(w io.Writer) error {
+		status := &req.Status
+		phase := string(status.Phase)
+		if req.Spec.Paused {
+			phase += " (paused)"
+		}
+		if req.Spec.DryRun {
+			phase += " (dry run)"
+		}
+		fmt.Fprintf(w, "Request:  %s/%s\n", req.Namespace, req.Name)
+		fmt.Fprintf(w, "Phase:    %s\n", phase)
+		fmt.Fprintf(w, "Message:  %s\n", status.Message)
+		if status.LastRunTime != nil {
+			fmt.Fprintf(w, "Last run: %s (%s)\n", status.LastRunTime.Format(time.RFC3339), since(status.LastRunTime.Time))
+		}
+		if status.NextRunTime != nil {
+			fmt.Fprintf(w, "Next run: %s (%s)\n", status.NextRunTime.Format(time.RFC3339), since(status.NextRunTime.Time))
+		}
+		if status.LastRunNow != "" {
+			fmt.Fprintf(w, "Run now:  %s handled\n", status.LastRunNow)
+		}
+		if open := status.CircuitOpenUntil; open != nil && open.After(time.Now()) {
+			fmt.Fprintf(w, "Circuit:  open until %s (%s)\n", open.Format(time.RFC3339), since(open.Time))
+		}
+		fmt.Fprintf(w, "Evicted:  %d in %d runs\n\n", status.TotalPodsEvicted, status.RunCount)
+
+		if len(status.Nodes) == 0 {
+			fmt.Fprintln(w, "No run has recorded a node distribution yet.")
+			return nil
+		}
+		tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "NODE\tPODS\tUNMANAGED\tMAX\tTARGET\tSOURCE\tEXCESS\tUTILIZATION")
+		for _, node := range status.Nodes {
+			maxPods, utilization := "-", "-"
+			if node.MaxPods > 0 {
+				maxPods = fmt.Sprint(node.MaxPods)
+			}
+			if node.TargetSource == rebalancer.TargetSourceLoad {
+				utilization = fmt.Sprintf("%d%%", node.UtilizationPercent)
+			}
+			excess := node.PodCount - node.Target
+			if excess < 0 {
+				excess = 0
+			}
+			fmt.Fprintf(tw, "%s\t%d\t%d\t%s\t%d\t%s\t%d\t%s\n", node.Name, node.PodCount, node.UnmanagedPods,
+				maxPods, node.Target, node.TargetSource, excess, utilization)
+		}
+		return tw.Flush()
+	})
+}
+
+// engine builds an engine reading the live cluster, configured like the manager's
+func (p *plugin) engine() *rebalancer.Engine {
+	engine := rebalancer.NewEngine(p.client)
+	engine.Reader = p.client
+	engine.OptInLabel = p.optInLabel
+	engine.LoadProviders = map[korev1alpha1.LoadSource]rebalancer.LoadProvider{
+		korev1alpha1.LoadSourceMetrics: rebalancer.NewMetricsAPISource(p.client),
+	}
+	if p.prometheusURL != "" {
+		engine.LoadProviders[korev1alpha1.LoadSourcePrometheus] = rebalancer.NewPrometheusProvider(
+			p.prometheusURL, &http.Client{Timeout: 30 * time.Second})
+	}
+	return engine
+}
+
+// runPlan plans a run against the live cluster with the manager's engine, without evicting
+func runPlan(ctx context.Context, p *plugin, req *korev1alpha1.RebalanceRequest) error {
+	plan, err := p.engine().Plan(ctx, req)
+	if err != nil {
+		return err
+	}
+	result := report.NewResult(req, plan)
+	return report.Print(p.out, p.output, result, result.PrintText)
+}
+
+// runExplain plans a run against the live cluster and explains the decision for one pod,
+// or for every managed pod when no pod is given
+func runExplain(ctx context.Context, p *plugin, req *korev1alpha1.RebalanceRequest) error {
+	if p.pod != "" {
+		key := types.NamespacedName{Namespace: p.namespace, Name: p.pod}
+		if namespace, name, ok := strings.Cut(p.pod, "/"); ok {
+			key = types.NamespacedName{Namespace: namespace, Name: name}
+		}
+		decision, err := p.engine().Explain(ctx, req, key)
+		if err != nil {
+			return err
+		}
+		return report.Print(p.out, p.output, decision, func(w io.Writer) error {
+			fmt.Fprintf(w, "Pod:      %s\n", decision.Pod)
+			if decision.Node != "" {
+				fmt.Fprintf(w, "Node:     %s\n", decision.Node)
+			}
+			if decision.Workload != "" {
+				fmt.Fprintf(w, "Workload: %s\n", decision.Workload)
+			}
+			fmt.Fprintf(w, "Selected: %t\n", decision.Selected())
+			fmt.Fprintf(w, "Reason:   %s\n", decision.Reason)
+			fmt.Fprintf(w, "Message:  %s\n", decision.Message)
+			return nil
+		})
+	}
+
+	plan, err := p.engine().Plan(ctx, req)
+	if err != nil {
+		return err
+	}
+	decisions := plan.Decisions()
+	return report.Print(p.out, p.output, decisions, func(w io.Writer) error {
+		if len(decisions) == 0 {
+			fmt.Fprintf(w, "No managed pods: %s\n", plan.Message)
+			return nil
+		}
+		tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "POD\tNODE\tREASON\tMESSAGE")
+		for _, decision := range decisions {
+			node := decision.Node
+			if node == "" {
+				node = "-"
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", decision.Pod, node, decision.Reason, decision.Message)
+		}
+		return tw.Flush()
+	})
+}
+
+// runPause sets spec.paused
+func runPause(ctx context.Context, p *plugin, req *korev1alpha1.RebalanceRequest) error {
+	return p.setPaused(ctx, req, true)
+}
+
+// runResume clears spec.paused
+func runResume(ctx context.Context, p *plugin, req *korev1alpha1.RebalanceRequest) error {
+	return p.setPaused(ctx, req, false)
+}
+
+func (p *plugin) setPaused(ctx context.Context, req *korev1alpha1.RebalanceRequest, paused bool) error {
+	verb := "resumed"
+	if paused {
+		verb = "paused"
+	}
+	if req.Spec.Paused == paused {
+		fmt.Fprintf(p.out, "rebalancerequest %s/%s already %s\n", req.Namespace, req.Name, verb)
+		return nil
+	}
+
+	patch := client.MergeFrom(req.DeepCopy())
+	req.Spec.Paused = paused
+	if err := p.client.Patch(ctx, req, patch); err != nil {
+		return err
+	}
+	fmt.Fprintf(p.out, "rebalancerequest %s/%s %s\n", req.Namespace, req.Name, verb)
+	return nil
+}
+
+// runNow sets the run-now annotation to the current time, so the manager runs at once
+func runNow(ctx context.Context, p *plugin, req *korev1alpha1.RebalanceRequest) error {
+	if req.Spec.Paused {
+		return fmt.Errorf("rebalancerequest %s/%s is paused, resume it first", req.Namespace, req.Name)
+	}
+
+	patch := client.MergeFrom(req.DeepCopy())
+	value := time.Now().UTC().Format(time.RFC3339)
+	if req.Annotations == nil {
+		req.Annotations = make(map[string]string)
+	}
+	req.Annotations[rebalancer.RunNowAnnotation] = value
+	if err := p.client.Patch(ctx, req, patch); err != nil {
+		return err
+	}
+	fmt.Fprintf(p.out, "rebalancerequest %s/%s run requested (%s=%s)\n", req.Namespace, req.Name, rebalancer.RunNowAnnotation, value)
+	return nil
+}
+
+// runHistory prints the evictions recorded in status, newest first
+func runHistory(_ context.Context, p *plugin, req *korev1alpha1.RebalanceRequest) error {
+	evictions := req.Status.RecentEvictions
+	return report.Print(p.out, p.output, evictions, func(w io.Writer) error {
+		if len(evictions) == 0 {
+			fmt.Fprintf(w, "No evictions recorded for %s/%s\n", req.Namespace, req.Name)
+			return nil
+		}
+		tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "TIME\tAGE\tPOD\tNODE\tWORKLOAD")
+		for i := len(evictions) - 1; i >= 0; i-- {
+			eviction := evictions[i]
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", eviction.Time.Format(time.RFC3339), since(eviction.Time.Time),
+				eviction.Pod, eviction.Node, eviction.Workload)
+		}
+		return tw.Flush()
+	})
+}
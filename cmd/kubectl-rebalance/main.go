@@ -0,0 +1,210 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	"k8s.io/client-go/tools/clientcmd"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	ctrllog "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	korev1alpha1 "github.com/cxfcxf/pod-rebalancer/api/v1alpha1"
+	"github.com/cxfcxf/pod-rebalancer/internal/rebalancer"
+	"github.com/cxfcxf/pod-rebalancer/internal/report"
+	"github.com/cxfcxf/pod-rebalancer/internal/simulator"
+)
+
+// command is a kubectl rebalance subcommand
+type command struct {
+	usage string
+	short string
+	run   func(ctx context.Context, p *plugin, req *korev1alpha1.RebalanceRequest) error
+}
+
+var commands = map[string]command{
+	"status":  {"status [NAME]", "Show the per-node distribution of the last run", runStatus},
+	"plan":    {"plan [NAME]", "Plan a run against the live cluster without evicting", runPlan},
+	"explain": {"explain [NAME] [-pod POD]", "Explain why pods would or would not be evicted", runExplain},
+	"pause":   {"pause [NAME]", "Stop the request from running", runPause},
+	"resume":  {"resume [NAME]", "Let a paused request run again", runResume},
+	"run-now": {"run-now [NAME]", "Run at once instead of waiting for the interval", runNow},
+	"history": {"history [NAME]", "List the recent evictions", runHistory},
+}
+
+// plugin holds the flags and client shared by all subcommands
+type plugin struct {
+	client    client.Client
+	namespace string
+	out       io.Writer
+
+	output        string
+	optInLabel    string
+	prometheusURL string
+	pod           string
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage(os.Stderr)
+		os.Exit(2)
+	}
+	name := os.Args[1]
+	if name == "help" || name == "-h" || name == "--help" {
+		usage(os.Stdout)
+		return
+	}
+	cmd, ok := commands[name]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n", name)
+		usage(os.Stderr)
+		os.Exit(2)
+	}
+
+	p := &plugin{out: os.Stdout}
+	var kubeconfig, kubeContext, namespace string
+	var verbose bool
+
+	fs := flag.NewFlagSet("kubectl rebalance "+name, flag.ExitOnError)
+	fs.StringVar(&kubeconfig, "kubeconfig", "", "Path to the kubeconfig file. Defaults to $KUBECONFIG or ~/.kube/config.")
+	fs.StringVar(&kubeContext, "context", "", "The kubeconfig context to use.")
+	fs.StringVar(&namespace, "namespace", "", "The namespace of the RebalanceRequest. Defaults to the context's namespace.")
+	fs.StringVar(&namespace, "n", "", "Shorthand for -namespace.")
+	fs.StringVar(&p.output, "o", report.OutputText, "Output format for status, plan, explain and history: text, json or yaml.")
+	fs.StringVar(&p.optInLabel, "opt-in-label", rebalancer.RebalanceEnabledLabel,
+		"The manager's --opt-in-label, used by plan and explain when the request does not set its own.")
+	fs.StringVar(&p.prometheusURL, "prometheus-url", "", "The manager's --prometheus-url, used by plan and explain for the Prometheus load source.")
+	fs.StringVar(&p.pod, "pod", "", "The pod explain reports on, as NAMESPACE/NAME or NAME in the -namespace. Omit to explain every managed pod.")
+	fs.BoolVar(&verbose, "v", false, "Log the engine's decisions to stderr.")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: kubectl rebalance %s [flags]\n\n%s.\n\n", cmd.usage, cmd.short)
+		fs.PrintDefaults()
+	}
+	args := parseInterspersed(fs, os.Args[2:])
+	if len(args) > 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	if verbose {
+		ctrllog.SetLogger(zap.New(zap.WriteTo(os.Stderr), zap.UseDevMode(true)))
+	} else {
+		ctrllog.SetLogger(logr.Discard())
+	}
+
+	if err := p.connect(kubeconfig, kubeContext, namespace); err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(1)
+	}
+
+	ctx := context.Background()
+	var requestName string
+	if len(args) == 1 {
+		requestName = args[0]
+	}
+	req, err := p.request(ctx, requestName)
+	if err == nil {
+		err = cmd.run(ctx, p, req)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(1)
+	}
+}
+
+func usage(w io.Writer) {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Fprintln(w, "Inspect and operate RebalanceRequests.")
+	fmt.Fprintln(w, "\nUsage: kubectl rebalance COMMAND [NAME] [flags]")
+	fmt.Fprintln(w, "\nCommands:")
+	for _, name := range names {
+		fmt.Fprintf(w, "  %-9s %s\n", name, commands[name].short)
+	}
+	fmt.Fprintln(w, "\nNAME may be omitted when the namespace holds a single RebalanceRequest.")
+	fmt.Fprintln(w, "Run kubectl rebalance COMMAND -h for the command's flags.")
+}
+
+// parseInterspersed parses flags before and after positional arguments, like kubectl,
+// and returns the positional arguments
+func parseInterspersed(fs *flag.FlagSet, args []string) []string {
+	var positional []string
+	for {
+		// ExitOnError: Parse exits on bad flags
+		_ = fs.Parse(args)
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// connect builds the client from the kubeconfig, resolving the namespace like kubectl
+func (p *plugin) connect(kubeconfig, kubeContext, namespace string) error {
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	rules.ExplicitPath = kubeconfig
+	overrides := &clientcmd.ConfigOverrides{CurrentContext: kubeContext}
+	overrides.Context.Namespace = namespace
+	clientConfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(rules, overrides)
+
+	config, err := clientConfig.ClientConfig()
+	if err != nil {
+		return err
+	}
+	if p.namespace, _, err = clientConfig.Namespace(); err != nil {
+		return err
+	}
+	p.client, err = client.New(config, client.Options{Scheme: simulator.NewScheme()})
+	return err
+}
+
+// request gets the named RebalanceRequest, or the only one in the namespace when name is empty
+func (p *plugin) request(ctx context.Context, name string) (*korev1alpha1.RebalanceRequest, error) {
+	if name != "" {
+		var req korev1alpha1.RebalanceRequest
+		if err := p.client.Get(ctx, client.ObjectKey{Namespace: p.namespace, Name: name}, &req); err != nil {
+			return nil, err
+		}
+		return &req, nil
+	}
+
+	var list korev1alpha1.RebalanceRequestList
+	if err := p.client.List(ctx, &list, client.InNamespace(p.namespace)); err != nil {
+		return nil, err
+	}
+	switch len(list.Items) {
+	case 0:
+		return nil, fmt.Errorf("no RebalanceRequests in namespace %s", p.namespace)
+	case 1:
+		return &list.Items[0], nil
+	default:
+		names := make([]string, 0, len(list.Items))
+		for _, item := range list.Items {
+			names = append(names, item.Name)
+		}
+		return nil, fmt.Errorf("namespace %s holds several RebalanceRequests, name one of: %s",
+			p.namespace, strings.Join(names, ", "))
+	}
+}
+
+// since formats a time relative to now, e.g. "3m ago" or "in 20s"
+func since(t time.Time) string {
+	d := time.Since(t).Round(time.Second)
+	if d < 0 {
+		return "in " + (-d).String()
+	}
+	return d.String() + " ago"
+}
@@ -1,24 +1,34 @@
 package main
 
 import (
+	"context"
 	"flag"
+	"net/http"
 	"os"
+	"time"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/selection"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
 
 	korev1alpha1 "github.com/cxfcxf/pod-rebalancer/api/v1alpha1"
 	"github.com/cxfcxf/pod-rebalancer/internal/controller"
+	"github.com/cxfcxf/pod-rebalancer/internal/debug"
 	"github.com/cxfcxf/pod-rebalancer/internal/rebalancer"
+	"github.com/cxfcxf/pod-rebalancer/internal/tracing"
 )
 
 var (
@@ -35,12 +45,47 @@ func main() {
 	var metricsAddr string
 	var enableLeaderElection bool
 	var probeAddr string
+	var optInLabel string
+	var prometheusURL string
+	var prometheusTimeout time.Duration
+	var eventDebounce time.Duration
+	var maxEvictionsPerMinute int
+	var maxNamespaceEvictionsPerMinute int
+	var evictionBurst int
+	var debugAddr string
+	var debugCertFile string
+	var debugKeyFile string
+	var traceOpts tracing.Options
 
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
+	flag.StringVar(&optInLabel, "opt-in-label", rebalancer.RebalanceEnabledLabel,
+		"The pod label that opts pods into rebalancing when a RebalanceRequest does not set its own.")
+	flag.StringVar(&prometheusURL, "prometheus-url", "",
+		"The Prometheus server queried by requests using the Prometheus load source. Empty disables the source.")
+	flag.IntVar(&maxEvictionsPerMinute, "max-evictions-per-minute", 0,
+		"The maximum number of evictions per minute across all RebalanceRequests. 0 means no limit.")
+	flag.IntVar(&maxNamespaceEvictionsPerMinute, "max-namespace-evictions-per-minute", 0,
+		"The maximum number of evictions per minute in any one namespace across all RebalanceRequests. 0 means no limit.")
+	flag.IntVar(&evictionBurst, "eviction-burst", 1,
+		"How many evictions may happen back to back before the per-minute limits pace them.")
+	flag.DurationVar(&eventDebounce, "event-debounce", controller.DefaultEventDebounce,
+		"How long node and pod events are collected before a RebalanceRequest runs early.")
+	flag.StringVar(&debugAddr, "debug-bind-address", "0",
+		"The address the authenticated debug API binds to. Set to 0 to disable it. "+
+			"Without a TLS certificate only loopback addresses are allowed.")
+	flag.StringVar(&debugCertFile, "debug-tls-cert-file", "", "The TLS certificate served by the debug API.")
+	flag.StringVar(&debugKeyFile, "debug-tls-key-file", "", "The TLS key served by the debug API.")
+	flag.StringVar(&traceOpts.Exporter, "trace-exporter", tracing.ExporterNone,
+		"Where spans are exported: none, otlp to send them to an OTLP gRPC collector, or stdout to write them as JSON.")
+	flag.StringVar(&traceOpts.Endpoint, "otlp-endpoint", "",
+		"The OTLP collector's host:port. Defaults to OTEL_EXPORTER_OTLP_ENDPOINT or localhost:4317.")
+	flag.BoolVar(&traceOpts.Insecure, "otlp-insecure", false, "Connect to the OTLP collector without TLS.")
+	flag.Float64Var(&traceOpts.SampleRatio, "trace-sample-ratio", 1, "The fraction of reconciles traced, from 0 to 1.")
+	flag.DurationVar(&prometheusTimeout, "prometheus-timeout", 30*time.Second, "The timeout for Prometheus queries.")
 
 	opts := zap.Options{
 		Development: true,
@@ -50,8 +95,35 @@ func main() {
 
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
 
+	traceOpts.Output = os.Stdout
+	shutdownTracing, err := tracing.Setup(context.Background(), "pod-rebalancer", traceOpts)
+	if err != nil {
+		setupLog.Error(err, "unable to set up tracing")
+		os.Exit(1)
+	}
+
+	// Only cache pods carrying the opt-in label; other pods are read directly when needed
+	optInRequirement, err := labels.NewRequirement(optInLabel, selection.Exists, nil)
+	if err != nil {
+		setupLog.Error(err, "invalid opt-in label", "label", optInLabel)
+		os.Exit(1)
+	}
+
+	// Only cache the ConfigMaps holding run reports
+	reportRequirement, err := labels.NewRequirement(controller.ReportLabel, selection.Exists, nil)
+	if err != nil {
+		setupLog.Error(err, "invalid report label")
+		os.Exit(1)
+	}
+
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
 		Scheme: scheme,
+		Cache: cache.Options{
+			ByObject: map[client.Object]cache.ByObject{
+				&corev1.Pod{}:       {Label: labels.NewSelector().Add(*optInRequirement)},
+				&corev1.ConfigMap{}: {Label: labels.NewSelector().Add(*reportRequirement)},
+			},
+		},
 		Metrics: metricsserver.Options{
 			BindAddress: metricsAddr,
 		},
@@ -64,14 +136,47 @@ func main() {
 		os.Exit(1)
 	}
 
+	if err := rebalancer.IndexPodNodeName(context.Background(), mgr.GetFieldIndexer()); err != nil {
+		setupLog.Error(err, "unable to index pods by node")
+		os.Exit(1)
+	}
+
 	// Create the rebalancer engine
 	engine := rebalancer.NewEngine(mgr.GetClient())
+	engine.Reader = mgr.GetAPIReader()
+	engine.OptInLabel = optInLabel
+	if maxEvictionsPerMinute > 0 || maxNamespaceEvictionsPerMinute > 0 {
+		engine.Budget = rebalancer.NewDisruptionBudget(maxEvictionsPerMinute, maxNamespaceEvictionsPerMinute, evictionBurst)
+	}
+	engine.LoadProviders = map[korev1alpha1.LoadSource]rebalancer.LoadProvider{
+		korev1alpha1.LoadSourceMetrics: rebalancer.NewMetricsAPISource(mgr.GetAPIReader()),
+	}
+	if prometheusURL != "" {
+		engine.LoadProviders[korev1alpha1.LoadSourcePrometheus] = rebalancer.NewPrometheusProvider(
+			prometheusURL, &http.Client{Timeout: prometheusTimeout})
+	}
+
+	// Serve the debug API when enabled, tracking runs for it
+	if debugAddr != "" && debugAddr != "0" {
+		engine.Runs = rebalancer.NewRunTracker()
+		if err := mgr.Add(&debug.Server{
+			Client:   mgr.GetClient(),
+			Engine:   engine,
+			Address:  debugAddr,
+			CertFile: debugCertFile,
+			KeyFile:  debugKeyFile,
+		}); err != nil {
+			setupLog.Error(err, "unable to set up debug server")
+			os.Exit(1)
+		}
+	}
 
 	// Set up RebalanceRequest controller
 	if err = (&controller.RebalanceRequestReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
-		Engine: engine,
+		Client:   mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		Engine:   engine,
+		Debounce: eventDebounce,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "RebalanceRequest")
 		os.Exit(1)
@@ -87,7 +192,15 @@ func main() {
 	}
 
 	setupLog.Info("starting manager")
-	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
+	err = mgr.Start(ctrl.SetupSignalHandler())
+
+	// Flush the spans still buffered for the collector
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		setupLog.Error(err, "failed to flush traces")
+	}
+	cancel()
+	if err != nil {
 		setupLog.Error(err, "problem running manager")
 		os.Exit(1)
 	}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/go-logr/logr"
+	ctrllog "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	"github.com/cxfcxf/pod-rebalancer/internal/rebalancer"
+	"github.com/cxfcxf/pod-rebalancer/internal/report"
+	"github.com/cxfcxf/pod-rebalancer/internal/simulator"
+)
+
+// fileList collects repeated -f flags
+type fileList []string
+
+func (f *fileList) String() string { return strings.Join(*f, ",") }
+
+func (f *fileList) Set(value string) error {
+	*f = append(*f, value)
+	return nil
+}
+
+func main() {
+	var files fileList
+	var requestName string
+	var optInLabel string
+	var output string
+	var rounds int
+	var schedulerName string
+	var seed int64
+	var verbose bool
+
+	flag.Var(&files, "f", "A YAML or JSON dump of Nodes, Pods and RebalanceRequests, e.g. from kubectl get -o yaml. Repeatable; - reads stdin.")
+	flag.StringVar(&requestName, "request", "", "The RebalanceRequest to simulate. Required if the dumps hold more than one.")
+	flag.StringVar(&optInLabel, "opt-in-label", rebalancer.RebalanceEnabledLabel,
+		"The pod label that opts pods into rebalancing when a RebalanceRequest does not set its own.")
+	flag.StringVar(&output, "o", report.OutputText, "Output format: text, json or yaml.")
+	flag.IntVar(&rounds, "rounds", 0,
+		"Simulate up to this many rebalance intervals, scheduling replacements between them, and report convergence. 0 plans a single run.")
+	flag.StringVar(&schedulerName, "scheduler", simulator.SchedulerLeastAllocated,
+		fmt.Sprintf("The scheduler model placing replacements with -rounds: %s.", strings.Join(simulator.Schedulers, ", ")))
+	flag.Int64Var(&seed, "seed", 1, "The seed for the random scheduler model.")
+	flag.BoolVar(&verbose, "v", false, "Log the engine's decisions to stderr.")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s -f snapshot.yaml [-f more.yaml] [flags]\n\n", os.Args[0])
+		fmt.Fprintln(flag.CommandLine.Output(), "Plans a rebalance run from cluster dumps without contacting a cluster.")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if len(files) == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if verbose {
+		ctrllog.SetLogger(zap.New(zap.WriteTo(os.Stderr), zap.UseDevMode(true)))
+	} else {
+		ctrllog.SetLogger(logr.Discard())
+	}
+
+	if err := run(files, requestName, optInLabel, output, rounds, schedulerName, seed); err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(1)
+	}
+}
+
+func run(files []string, requestName, optInLabel, output string, rounds int, schedulerName string, seed int64) error {
+	scheme := simulator.NewScheme()
+	snapshot, err := simulator.LoadSnapshot(scheme, files...)
+	if err != nil {
+		return err
+	}
+	if len(snapshot.Ignored) > 0 {
+		fmt.Fprintf(os.Stderr, "ignoring unknown kinds: %s\n", strings.Join(snapshot.Ignored, ", "))
+	}
+
+	req, err := snapshot.Request(requestName)
+	if err != nil {
+		return err
+	}
+
+	opts := simulator.Options{OptInLabel: optInLabel}
+	if rounds > 0 {
+		scheduler, err := simulator.NewScheduler(schedulerName, seed)
+		if err != nil {
+			return err
+		}
+		convergence, err := simulator.SimulateConvergence(context.Background(), scheme, snapshot, req, simulator.ConvergenceOptions{
+			Options:       opts,
+			MaxRounds:     rounds,
+			Scheduler:     scheduler,
+			SchedulerName: schedulerName,
+		})
+		if err != nil {
+			return err
+		}
+		return report.Print(os.Stdout, output, convergence, convergence.PrintText)
+	}
+
+	result, err := simulator.Simulate(context.Background(), scheme, snapshot, req, opts)
+	if err != nil {
+		return err
+	}
+	return report.Print(os.Stdout, output, result, result.PrintText)
+}
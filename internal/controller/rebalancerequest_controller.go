@@ -3,23 +3,50 @@ package controller
 import (
 	"context"
 	"fmt"
+	"sort"
 	"time"
 
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
 	korev1alpha1 "github.com/cxfcxf/pod-rebalancer/api/v1alpha1"
 	"github.com/cxfcxf/pod-rebalancer/internal/rebalancer"
+	"github.com/cxfcxf/pod-rebalancer/internal/tracing"
 )
 
+// maxSkippedExamples limits how many example pods are listed per skip reason in status
+const maxSkippedExamples = 5
+
+// maxRecentEvictions bounds the eviction history kept in status
+const maxRecentEvictions = 200
+
+// pausedMessage is the status message of a paused request
+const pausedMessage = "Rebalancer paused"
+
+// tracer records a span for every reconcile
+var tracer = otel.Tracer("github.com/cxfcxf/pod-rebalancer/internal/controller")
+
 // RebalanceRequestReconciler reconciles a RebalanceRequest object
 type RebalanceRequestReconciler struct {
 	client.Client
 	Scheme *runtime.Scheme
 	Engine *rebalancer.Engine
+
+	// Debounce is how long node and pod events are collected before triggering an early run
+	Debounce time.Duration
+
+	triggers eventTriggers
 }
 
 // +kubebuilder:rbac:groups=kore.boring.io,resources=rebalancerequests,verbs=get;list;watch;create;update;patch;delete
@@ -27,19 +54,48 @@ type RebalanceRequestReconciler struct {
 // +kubebuilder:rbac:groups=kore.boring.io,resources=rebalancerequests/finalizers,verbs=update
 // +kubebuilder:rbac:groups="",resources=pods,verbs=get;list;watch
 // +kubebuilder:rbac:groups="",resources=pods/eviction,verbs=create
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch;create;update;patch
 // +kubebuilder:rbac:groups="",resources=nodes,verbs=get;list;watch
 // +kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=persistentvolumeclaims,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=persistentvolumes,verbs=get;list;watch
+// +kubebuilder:rbac:groups=apps,resources=replicasets;deployments;statefulsets,verbs=get;list;watch
+// +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch
+// +kubebuilder:rbac:groups=metrics.k8s.io,resources=nodes;pods,verbs=get;list
+// +kubebuilder:rbac:groups=authentication.k8s.io,resources=tokenreviews,verbs=create
+// +kubebuilder:rbac:groups=authorization.k8s.io,resources=subjectaccessreviews,verbs=create
 
 // Reconcile handles RebalanceRequest reconciliation
 func (r *RebalanceRequestReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	ctx, span := tracer.Start(ctx, "Reconcile", trace.WithAttributes(tracing.Request(req.NamespacedName)))
+	result, err := r.reconcile(ctx, req)
+	if result.RequeueAfter > 0 {
+		span.SetAttributes(attribute.String("rebalancer.requeue_after", result.RequeueAfter.String()))
+	}
+	tracing.End(span, err)
+	return result, err
+}
+
+func (r *RebalanceRequestReconciler) reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	logger := log.FromContext(ctx)
+	span := trace.SpanFromContext(ctx)
 
 	// Fetch the RebalanceRequest
 	var rebalanceReq korev1alpha1.RebalanceRequest
 	if err := r.Get(ctx, req.NamespacedName, &rebalanceReq); err != nil {
+		if apierrors.IsNotFound(err) {
+			r.Engine.Runs.Forget(req.NamespacedName)
+			r.triggers.forget(req.NamespacedName)
+		}
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
 
+	// A finished one-shot request only waits for its TTL
+	if rebalanceReq.Status.Phase == korev1alpha1.RebalancePhaseCompleted ||
+		rebalanceReq.Status.Phase == korev1alpha1.RebalancePhaseFailed {
+		return r.expireFinished(ctx, &rebalanceReq)
+	}
+
 	// Get interval (default 60 seconds)
 	interval := time.Duration(rebalanceReq.Spec.IntervalSeconds) * time.Second
 	if interval < 30*time.Second {
@@ -60,22 +116,76 @@ func (r *RebalanceRequestReconciler) Reconcile(ctx context.Context, req ctrl.Req
 		return ctrl.Result{Requeue: true}, nil
 	}
 
-	// Check if it's time to run
+	// A paused request waits for the spec change that resumes it
+	if rebalanceReq.Spec.Paused {
+		r.triggers.forget(req.NamespacedName)
+		if rebalanceReq.Status.Message != pausedMessage {
+			rebalanceReq.Status.Message = pausedMessage
+			if err := r.Status().Update(ctx, &rebalanceReq); err != nil {
+				logger.Error(err, "Failed to update status")
+				return ctrl.Result{RequeueAfter: 5 * time.Second}, err
+			}
+		}
+		span.SetAttributes(tracing.Result("Paused"))
+		return ctrl.Result{}, nil
+	}
+
+	// Check if it's time to run, or if a cluster event brings the run forward
+	due := time.Now()
 	if rebalanceReq.Status.NextRunTime != nil {
-		if time.Now().Before(rebalanceReq.Status.NextRunTime.Time) {
-			waitDuration := time.Until(rebalanceReq.Status.NextRunTime.Time)
-			return ctrl.Result{RequeueAfter: waitDuration}, nil
+		due = rebalanceReq.Status.NextRunTime.Time
+	}
+	triggered := false
+	if eventTime, ok := r.triggers.get(req.NamespacedName); ok {
+		eventDue := eventTime.Add(r.Debounce)
+		if last := rebalanceReq.Status.LastRunTime; last != nil {
+			minGap := time.Duration(rebalanceReq.Spec.MinRunGapSeconds) * time.Second
+			if gapDue := last.Add(minGap); eventDue.Before(gapDue) {
+				eventDue = gapDue
+			}
 		}
+		if eventDue.Before(due) {
+			due = eventDue
+			triggered = true
+		}
+	}
+	// Nothing runs while the circuit breaker is open
+	if open := rebalanceReq.Status.CircuitOpenUntil; open != nil && due.Before(open.Time) {
+		due = open.Time
+		triggered = false
+	}
+	// A new run-now value runs at once, even during the cool-down; the guards still apply
+	runNow := rebalanceReq.Annotations[rebalancer.RunNowAnnotation]
+	if runNow != "" && runNow != rebalanceReq.Status.LastRunNow {
+		due = time.Now()
+	} else {
+		runNow = ""
 	}
+	if time.Now().Before(due) {
+		span.SetAttributes(tracing.Result("NotDue"))
+		return ctrl.Result{RequeueAfter: time.Until(due)}, nil
+	}
+	trigger := TriggerSchedule
+	switch {
+	case runNow != "":
+		trigger = TriggerRunNow
+	case triggered:
+		trigger = TriggerEvent
+	}
+	span.SetAttributes(tracing.Result("Ran"), attribute.String("rebalancer.trigger", trigger))
 
 	// Execute the rebalance
 	logger.Info("Running rebalance check",
 		"name", rebalanceReq.Name,
 		"namespace", rebalanceReq.Namespace,
 		"run", rebalanceReq.Status.RunCount+1,
+		"triggeredByEvent", triggered,
+		"runNow", runNow,
 	)
 
+	runStart := time.Now()
 	result := r.Engine.ExecuteRebalance(ctx, &rebalanceReq)
+	r.triggers.clear(req.NamespacedName, runStart)
 	now := metav1.Now()
 
 	// Update status
@@ -83,11 +193,40 @@ func (r *RebalanceRequestReconciler) Reconcile(ctx context.Context, req ctrl.Req
 	rebalanceReq.Status.TotalPodsEvicted += result.PodsEvicted
 	rebalanceReq.Status.RunCount++
 	rebalanceReq.Status.LastRunTime = &now
+	if runNow != "" {
+		rebalanceReq.Status.LastRunNow = runNow
+	}
+	rebalanceReq.Status.Nodes = nodeDistribution(result.Nodes)
+	rebalanceReq.Status.SkippedPods = summarizeSkippedPods(result.Skipped)
+	rebalanceReq.Status.RecentEvictions = recordEvictions(rebalanceReq.Status.RecentEvictions,
+		result.Evictions, now.Add(-rebalancer.EvictionWindow(&rebalanceReq.Spec)))
 
 	// Schedule next run
 	nextRun := metav1.NewTime(now.Add(interval))
 	rebalanceReq.Status.NextRunTime = &nextRun
 
+	// Open the circuit breaker for the cool-down when a guard tripped, otherwise close it
+	if result.Tripped != "" {
+		openUntil := metav1.NewTime(now.Add(rebalancer.CircuitBreakerCooldown(&rebalanceReq.Spec)))
+		rebalanceReq.Status.CircuitOpenUntil = &openUntil
+		meta.SetStatusCondition(&rebalanceReq.Status.Conditions, metav1.Condition{
+			Type:               korev1alpha1.ConditionTypeDegraded,
+			Status:             metav1.ConditionTrue,
+			Reason:             result.Tripped,
+			Message:            result.Message,
+			ObservedGeneration: rebalanceReq.Generation,
+		})
+	} else if result.Error == nil {
+		rebalanceReq.Status.CircuitOpenUntil = nil
+		meta.SetStatusCondition(&rebalanceReq.Status.Conditions, metav1.Condition{
+			Type:               korev1alpha1.ConditionTypeDegraded,
+			Status:             metav1.ConditionFalse,
+			Reason:             "GuardsPassed",
+			Message:            "Circuit breaker closed",
+			ObservedGeneration: rebalanceReq.Generation,
+		})
+	}
+
 	if result.Error != nil {
 		rebalanceReq.Status.Message = fmt.Sprintf("Run %d error: %s", rebalanceReq.Status.RunCount, result.Error.Error())
 		logger.Error(result.Error, "Rebalance check failed, will retry")
@@ -101,17 +240,162 @@ func (r *RebalanceRequestReconciler) Reconcile(ctx context.Context, req ctrl.Req
 		}
 	}
 
+	// Record the run's report when requested
+	if rebalanceReq.Spec.Report != nil {
+		report := rebalancer.NewReport(&rebalanceReq, rebalanceReq.Status.RunCount, trigger, runStart, now.Time, result)
+		if err := r.writeReport(ctx, &rebalanceReq, report); err != nil {
+			logger.Error(err, "Failed to write run report")
+		} else {
+			rebalanceReq.Status.ReportConfigMap = reportConfigMapName(&rebalanceReq)
+		}
+	}
+
+	finished := rebalanceReq.Spec.Mode == korev1alpha1.RunModeOnce && finishOnce(&rebalanceReq, result, now)
+
 	if err := r.Status().Update(ctx, &rebalanceReq); err != nil {
 		logger.Error(err, "Failed to update status")
 		return ctrl.Result{RequeueAfter: 5 * time.Second}, err
 	}
 
+	if finished {
+		logger.Info("One-shot rebalance finished", "phase", rebalanceReq.Status.Phase, "runs", rebalanceReq.Status.RunCount)
+		return r.expireFinished(ctx, &rebalanceReq)
+	}
 	return ctrl.Result{RequeueAfter: interval}, nil
 }
 
+// finishOnce moves a one-shot request to Completed once a run's plan finds nothing to evict
+// and nothing held back by the workload or eviction history guards, or to Failed when it runs
+// out of attempts. Only a run that evicted or tried to evict pods counts as an attempt.
+// Returns true if the request finished.
+func finishOnce(req *korev1alpha1.RebalanceRequest, result rebalancer.RebalanceResult, now metav1.Time) bool {
+	maxAttempts := req.Spec.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = 5
+	}
+	if !req.Spec.DryRun && (result.PodsEvicted > 0 || result.FailedEvictions > 0) {
+		req.Status.Attempts++
+	}
+
+	// A dry run evicts nothing, so its first clean run completes it
+	balanced := result.Error == nil && result.Tripped == "" && result.Plan != nil &&
+		(result.Plan.Balanced() || req.Spec.DryRun)
+	switch {
+	case balanced:
+		req.Status.Phase = korev1alpha1.RebalancePhaseCompleted
+		req.Status.Message = fmt.Sprintf("Completed after %d runs: %s", req.Status.RunCount, result.Message)
+	case req.Status.Attempts >= maxAttempts:
+		req.Status.Phase = korev1alpha1.RebalancePhaseFailed
+		req.Status.Message = fmt.Sprintf("Not balanced after %d attempts (%s)", req.Status.Attempts, req.Status.Message)
+	default:
+		return false
+	}
+	req.Status.CompletionTime = &now
+	req.Status.NextRunTime = nil
+	return true
+}
+
+// expireFinished deletes a finished request once its TTL has passed
+func (r *RebalanceRequestReconciler) expireFinished(ctx context.Context, req *korev1alpha1.RebalanceRequest) (ctrl.Result, error) {
+	ttl := req.Spec.TTLSecondsAfterFinished
+	if ttl == nil || req.Status.CompletionTime == nil {
+		return ctrl.Result{}, nil
+	}
+	if wait := time.Until(req.Status.CompletionTime.Add(time.Duration(*ttl) * time.Second)); wait > 0 {
+		return ctrl.Result{RequeueAfter: wait}, nil
+	}
+
+	log.FromContext(ctx).Info("Deleting finished RebalanceRequest", "name", req.Name, "namespace", req.Namespace)
+	return ctrl.Result{}, client.IgnoreNotFound(r.Delete(ctx, req))
+}
+
+// nodeDistribution converts the engine's node table into status form
+func nodeDistribution(nodeCounts []rebalancer.NodePodCount) []korev1alpha1.NodeDistribution {
+	nodes := make([]korev1alpha1.NodeDistribution, 0, len(nodeCounts))
+	for _, nc := range nodeCounts {
+		dist := korev1alpha1.NodeDistribution{
+			Name:          nc.NodeName,
+			PodCount:      int32(nc.PodCount),
+			UnmanagedPods: int32(nc.Unmanaged),
+			UnderPressure: nc.Pressure,
+			Target:        int32(nc.Target),
+			TargetSource:  nc.TargetSource,
+		}
+		if nc.MaxPods > 0 {
+			dist.MaxPods = int32(nc.MaxPods)
+		}
+		if nc.TargetSource == rebalancer.TargetSourceLoad {
+			dist.UtilizationPercent = int32(nc.Utilization * 100)
+		}
+		nodes = append(nodes, dist)
+	}
+	return nodes
+}
+
+// recordEvictions appends the run's evictions to the history, dropping records older than the cutoff
+func recordEvictions(history []korev1alpha1.EvictionRecord, evictions []rebalancer.Eviction, cutoff time.Time) []korev1alpha1.EvictionRecord {
+	records := make([]korev1alpha1.EvictionRecord, 0, len(history)+len(evictions))
+	for _, record := range history {
+		if !record.Time.Time.Before(cutoff) {
+			records = append(records, record)
+		}
+	}
+	for _, eviction := range evictions {
+		records = append(records, korev1alpha1.EvictionRecord{
+			Pod:      eviction.Namespace + "/" + eviction.Name,
+			Workload: eviction.Workload.String(),
+			Node:     eviction.Node,
+			Time:     metav1.NewTime(eviction.Time),
+		})
+	}
+
+	// Keep the newest records
+	if len(records) > maxRecentEvictions {
+		records = records[len(records)-maxRecentEvictions:]
+	}
+	return records
+}
+
+// summarizeSkippedPods groups skipped pods by reason, most common first
+func summarizeSkippedPods(skipped []rebalancer.SkippedPod) []korev1alpha1.SkippedPodsSummary {
+	byReason := make(map[rebalancer.SkipReason]*korev1alpha1.SkippedPodsSummary)
+	var summaries []*korev1alpha1.SkippedPodsSummary
+	for _, pod := range skipped {
+		summary, ok := byReason[pod.Reason]
+		if !ok {
+			summary = &korev1alpha1.SkippedPodsSummary{Reason: string(pod.Reason)}
+			byReason[pod.Reason] = summary
+			summaries = append(summaries, summary)
+		}
+		summary.Count++
+		if len(summary.Examples) < maxSkippedExamples {
+			summary.Examples = append(summary.Examples, pod.Namespace+"/"+pod.Name)
+		}
+	}
+
+	sort.SliceStable(summaries, func(i, j int) bool {
+		return summaries[i].Count > summaries[j].Count
+	})
+
+	result := make([]korev1alpha1.SkippedPodsSummary, 0, len(summaries))
+	for _, summary := range summaries {
+		result = append(result, *summary)
+	}
+	return result
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *RebalanceRequestReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	// Evictions are recorded as they happen, before the resulting pod events arrive
+	r.Engine.OnEvict = r.triggers.evict
+
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&korev1alpha1.RebalanceRequest{}).
+		Watches(&corev1.Node{}, handler.EnqueueRequestsFromMapFunc(r.requestsForNode),
+			builder.WithPredicates(nodeEventPredicate())).
+		// The pod cache only holds pods with the opt-in label, so pods opted in by their
+		// namespace default produce no events and are rebalanced on the interval
+		Watches(&corev1.Pod{}, handler.EnqueueRequestsFromMapFunc(r.requestsForPod),
+			builder.WithPredicates(r.triggers.podEventPredicate())).
 		Complete(r)
 }
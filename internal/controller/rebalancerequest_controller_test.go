@@ -0,0 +1,105 @@
+package controller
+
+import (
+	"errors"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	korev1alpha1 "github.com/cxfcxf/pod-rebalancer/api/v1alpha1"
+	"github.com/cxfcxf/pod-rebalancer/internal/rebalancer"
+)
+
+func TestFinishOnce(t *testing.T) {
+	balanced := &rebalancer.Plan{}
+	unbalanced := &rebalancer.Plan{Victims: []corev1.Pod{*testPod("newest", "a", 0)}}
+
+	tests := []struct {
+		name         string
+		dryRun       bool
+		attempts     int32
+		result       rebalancer.RebalanceResult
+		wantFinished bool
+		wantPhase    korev1alpha1.RebalancePhase
+		wantAttempts int32
+	}{
+		{
+			name:         "balanced",
+			attempts:     2,
+			result:       rebalancer.RebalanceResult{Plan: balanced},
+			wantFinished: true,
+			wantPhase:    korev1alpha1.RebalancePhaseCompleted,
+			wantAttempts: 2,
+		},
+		{
+			name:         "dry run",
+			dryRun:       true,
+			result:       rebalancer.RebalanceResult{Plan: unbalanced, PodsEvicted: 1},
+			wantFinished: true,
+			wantPhase:    korev1alpha1.RebalancePhaseCompleted,
+		},
+		{
+			name:         "evicted",
+			attempts:     1,
+			result:       rebalancer.RebalanceResult{Plan: unbalanced, PodsEvicted: 1},
+			wantPhase:    korev1alpha1.RebalancePhaseActive,
+			wantAttempts: 2,
+		},
+		{
+			// A failed eviction is an attempt, not a success
+			name:         "eviction failed",
+			attempts:     1,
+			result:       rebalancer.RebalanceResult{Plan: unbalanced, FailedEvictions: 1},
+			wantPhase:    korev1alpha1.RebalancePhaseActive,
+			wantAttempts: 2,
+		},
+		{
+			name:         "tripped",
+			attempts:     4,
+			result:       rebalancer.RebalanceResult{Plan: balanced, Tripped: rebalancer.GuardEvictionFailures},
+			wantPhase:    korev1alpha1.RebalancePhaseActive,
+			wantAttempts: 4,
+		},
+		{
+			name:         "errored",
+			attempts:     4,
+			result:       rebalancer.RebalanceResult{Error: errors.New("list failed")},
+			wantPhase:    korev1alpha1.RebalancePhaseActive,
+			wantAttempts: 4,
+		},
+		{
+			name:         "exhausted",
+			attempts:     4,
+			result:       rebalancer.RebalanceResult{Plan: unbalanced, PodsEvicted: 1},
+			wantFinished: true,
+			wantPhase:    korev1alpha1.RebalancePhaseFailed,
+			wantAttempts: 5,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := &korev1alpha1.RebalanceRequest{
+				Spec: korev1alpha1.RebalanceRequestSpec{Mode: korev1alpha1.RunModeOnce, MaxAttempts: 5, DryRun: tt.dryRun},
+				Status: korev1alpha1.RebalanceRequestStatus{
+					Phase:    korev1alpha1.RebalancePhaseActive,
+					RunCount: 10, // runs from before the switch to Once do not count
+					Attempts: tt.attempts,
+				},
+			}
+			finished := finishOnce(req, tt.result, metav1.Now())
+			if finished != tt.wantFinished {
+				t.Errorf("finishOnce() = %v, want %v", finished, tt.wantFinished)
+			}
+			if req.Status.Phase != tt.wantPhase {
+				t.Errorf("phase = %s, want %s", req.Status.Phase, tt.wantPhase)
+			}
+			if req.Status.Attempts != tt.wantAttempts {
+				t.Errorf("attempts = %d, want %d", req.Status.Attempts, tt.wantAttempts)
+			}
+			if finished && req.Status.CompletionTime == nil {
+				t.Error("finished request has no completion time")
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	korev1alpha1 "github.com/cxfcxf/pod-rebalancer/api/v1alpha1"
+	"github.com/cxfcxf/pod-rebalancer/internal/rebalancer"
+)
+
+// ReportLabel marks report ConfigMaps with the name of their RebalanceRequest.
+// The manager only caches ConfigMaps carrying it.
+const ReportLabel = "kore.boring.io/rebalance-request"
+
+// What brought a run forward, as recorded in its report
+const (
+	TriggerSchedule = "Schedule"
+	TriggerEvent    = "Event"
+	TriggerRunNow   = "RunNow"
+)
+
+const (
+	// defaultReportHistory is the number of reports kept when a request does not set a limit
+	defaultReportHistory = 10
+
+	// maxReportBytes keeps report ConfigMaps well under the 1MiB object size limit
+	maxReportBytes = 900 * 1024
+
+	// reportKeyPrefix starts the data key of every report, followed by the zero-padded run number
+	reportKeyPrefix = "run-"
+)
+
+// reportConfigMapName is the name of the ConfigMap holding a request's reports
+func reportConfigMapName(req *korev1alpha1.RebalanceRequest) string {
+	return req.Name + "-rebalance-report"
+}
+
+// reportKey is the data key of a run's report. Zero padding makes keys sort by run.
+func reportKey(run int32) string {
+	return fmt.Sprintf("%s%08d.json", reportKeyPrefix, run)
+}
+
+// writeReport adds the report to the request's report ConfigMap, owned by the request,
+// dropping the oldest reports beyond the history limit or size budget
+func (r *RebalanceRequestReconciler) writeReport(ctx context.Context, req *korev1alpha1.RebalanceRequest, report *rebalancer.Report) error {
+	data, err := json.Marshal(report)
+	if err != nil {
+		return err
+	}
+	limit := defaultReportHistory
+	if req.Spec.Report.HistoryLimit > 0 {
+		limit = int(req.Spec.Report.HistoryLimit)
+	}
+
+	cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: reportConfigMapName(req), Namespace: req.Namespace}}
+	_, err = controllerutil.CreateOrUpdate(ctx, r.Client, cm, func() error {
+		if cm.Labels == nil {
+			cm.Labels = make(map[string]string)
+		}
+		cm.Labels[ReportLabel] = req.Name
+		if cm.Data == nil {
+			cm.Data = make(map[string]string)
+		}
+		cm.Data[reportKey(report.Run)] = string(data)
+		trimReports(cm.Data, limit, maxReportBytes)
+		return controllerutil.SetControllerReference(req, cm, r.Scheme)
+	})
+	return err
+}
+
+// trimReports drops the oldest reports until at most limit remain and their size fits in maxBytes.
+// The newest report is always kept.
+func trimReports(data map[string]string, limit, maxBytes int) {
+	var keys []string
+	size := 0
+	for key, value := range data {
+		if strings.HasPrefix(key, reportKeyPrefix) {
+			keys = append(keys, key)
+			size += len(key) + len(value)
+		}
+	}
+	sort.Strings(keys)
+
+	for len(keys) > 1 && (len(keys) > limit || size > maxBytes) {
+		size -= len(keys[0]) + len(data[keys[0]])
+		delete(data, keys[0])
+		keys = keys[1:]
+	}
+}
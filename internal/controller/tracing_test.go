@@ -0,0 +1,163 @@
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	korev1alpha1 "github.com/cxfcxf/pod-rebalancer/api/v1alpha1"
+	"github.com/cxfcxf/pod-rebalancer/internal/rebalancer"
+	"github.com/cxfcxf/pod-rebalancer/internal/tracing"
+)
+
+// testNode returns a ready node
+func testNode(name string) *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Status: corev1.NodeStatus{
+			Allocatable: corev1.ResourceList{corev1.ResourcePods: resource.MustParse("110")},
+			Conditions:  []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionTrue}},
+		},
+	}
+}
+
+// testPod returns a running, opted-in pod owned by a ReplicaSet. Pods created later are newer.
+func testPod(name, node string, age time.Duration) *corev1.Pod {
+	controller := true
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         "shop",
+			Labels:            map[string]string{rebalancer.RebalanceEnabledLabel: "true"},
+			CreationTimestamp: metav1.NewTime(time.Now().Add(-age)),
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion: "apps/v1",
+				Kind:       "ReplicaSet",
+				Name:       "web",
+				UID:        "web",
+				Controller: &controller,
+			}},
+		},
+		Spec:   corev1.PodSpec{NodeName: node},
+		Status: corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+}
+
+// spanAttributes returns the span's attributes by key
+func spanAttributes(span tracetest.SpanStub) map[attribute.Key]attribute.Value {
+	attrs := make(map[attribute.Key]attribute.Value, len(span.Attributes))
+	for _, kv := range span.Attributes {
+		attrs[kv.Key] = kv.Value
+	}
+	return attrs
+}
+
+func TestReconcileTracesRunAndEvictions(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() { _ = provider.Shutdown(context.Background()) })
+
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := korev1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	request := &korev1alpha1.RebalanceRequest{
+		ObjectMeta: metav1.ObjectMeta{Name: "shop", Namespace: "default"},
+		Status:     korev1alpha1.RebalanceRequestStatus{Phase: korev1alpha1.RebalancePhaseActive},
+	}
+	c := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithStatusSubresource(&korev1alpha1.RebalanceRequest{}).
+		WithObjects(
+			request,
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "shop"}},
+			testNode("a"), testNode("b"),
+			testPod("oldest", "a", 4*time.Hour), testPod("old", "a", 3*time.Hour),
+			testPod("new", "a", 2*time.Hour), testPod("newest", "a", time.Hour),
+		).
+		Build()
+	r := &RebalanceRequestReconciler{Client: c, Scheme: scheme, Engine: rebalancer.NewEngine(c)}
+
+	key := types.NamespacedName{Namespace: "default", Name: "shop"}
+	if _, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: key}); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+
+	spans := exporter.GetSpans()
+	byName := make(map[string]tracetest.SpanStub, len(spans))
+	for _, span := range spans {
+		byName[span.Name] = span
+	}
+	// 4 pods over 2 nodes give a target of 3, so the run evicts a's newest pod in one batch
+	parents := []struct{ child, parent string }{
+		{"ExecuteRebalance", "Reconcile"},
+		{"Plan", "ExecuteRebalance"},
+		{"ListCandidates", "Plan"},
+		{"CalculateTargets", "Plan"},
+		{"EvictBatch", "ExecuteRebalance"},
+		{"EvictPod", "EvictBatch"},
+	}
+	for _, p := range parents {
+		child, ok := byName[p.child]
+		if !ok {
+			t.Fatalf("no %s span in %d spans", p.child, len(spans))
+		}
+		parent, ok := byName[p.parent]
+		if !ok {
+			t.Fatalf("no %s span in %d spans", p.parent, len(spans))
+		}
+		if child.Parent.SpanID() != parent.SpanContext.SpanID() {
+			t.Errorf("%s span's parent is not the %s span", p.child, p.parent)
+		}
+	}
+	if byName["Reconcile"].Parent.IsValid() {
+		t.Error("Reconcile span has a parent, want a root span")
+	}
+
+	want := map[string]map[attribute.Key]attribute.Value{
+		"Reconcile": {
+			tracing.RequestKey: attribute.StringValue(key.String()),
+			tracing.ResultKey:  attribute.StringValue("Ran"),
+		},
+		"ExecuteRebalance": {
+			tracing.RequestKey:        attribute.StringValue(key.String()),
+			"rebalancer.pods_evicted": attribute.IntValue(1),
+		},
+		"EvictBatch": {
+			tracing.BatchKey:        attribute.IntValue(1),
+			"rebalancer.batch_size": attribute.IntValue(1),
+		},
+		"EvictPod": {
+			semconv.K8SNamespaceNameKey: attribute.StringValue("shop"),
+			semconv.K8SPodNameKey:       attribute.StringValue("newest"),
+			semconv.K8SNodeNameKey:      attribute.StringValue("a"),
+			tracing.ResultKey:           attribute.StringValue("Evicted"),
+		},
+	}
+	for name, attrs := range want {
+		got := spanAttributes(byName[name])
+		for k, v := range attrs {
+			if got[k] != v {
+				t.Errorf("%s span attribute %s = %v, want %v", name, k, got[k].Emit(), v.Emit())
+			}
+		}
+	}
+}
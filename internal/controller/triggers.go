@@ -0,0 +1,248 @@
+package controller
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	korev1alpha1 "github.com/cxfcxf/pod-rebalancer/api/v1alpha1"
+	"github.com/cxfcxf/pod-rebalancer/internal/rebalancer"
+)
+
+// DefaultEventDebounce is how long cluster events are collected before an early run
+const DefaultEventDebounce = 10 * time.Second
+
+// ownEventTTL is how long the deletion of an evicted pod and the scheduling of its
+// replacement are expected before they count as cluster events again
+const ownEventTTL = 10 * time.Minute
+
+// eventTriggers records the first cluster event since the last run of each request.
+// It also remembers the rebalancer's own evictions, so that the evicted pods going away
+// and their replacements being scheduled do not trigger another run.
+type eventTriggers struct {
+	mu    sync.Mutex
+	first map[types.NamespacedName]time.Time
+
+	// evicted holds when each evicted pod was evicted
+	evicted map[types.NamespacedName]time.Time
+	// replacements counts the replacements expected from each evicted pod's controller
+	replacements map[types.UID][]time.Time
+}
+
+// add records an event for the request unless one is already pending
+func (t *eventTriggers) add(key types.NamespacedName) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.first == nil {
+		t.first = make(map[types.NamespacedName]time.Time)
+	}
+	if _, ok := t.first[key]; !ok {
+		t.first[key] = time.Now()
+	}
+}
+
+// get returns when the pending event for the request was recorded
+func (t *eventTriggers) get(key types.NamespacedName) (time.Time, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	at, ok := t.first[key]
+	return at, ok
+}
+
+// clear drops the pending event for the request if it was recorded before the given time,
+// so events that arrive while a run is in progress trigger another run
+func (t *eventTriggers) clear(key types.NamespacedName, before time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if at, ok := t.first[key]; ok && !at.After(before) {
+		delete(t.first, key)
+	}
+}
+
+// forget drops the pending event of a request that was deleted or paused
+func (t *eventTriggers) forget(key types.NamespacedName) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.first, key)
+}
+
+// evict records a pod the rebalancer evicted and the replacement its controller will create
+func (t *eventTriggers) evict(pod *corev1.Pod) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+	t.prune(now)
+	if t.evicted == nil {
+		t.evicted = make(map[types.NamespacedName]time.Time)
+		t.replacements = make(map[types.UID][]time.Time)
+	}
+	t.evicted[client.ObjectKeyFromObject(pod)] = now
+	if owner := metav1.GetControllerOf(pod); owner != nil {
+		t.replacements[owner.UID] = append(t.replacements[owner.UID], now)
+	}
+}
+
+// ownDeletion returns true if the pod was deleted because the rebalancer evicted it
+func (t *eventTriggers) ownDeletion(pod *corev1.Pod) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.prune(time.Now())
+	key := client.ObjectKeyFromObject(pod)
+	if _, ok := t.evicted[key]; !ok {
+		return false
+	}
+	delete(t.evicted, key)
+	return true
+}
+
+// ownReplacement returns true if the scheduled pod replaces one the rebalancer evicted,
+// consuming one expected replacement of its controller
+func (t *eventTriggers) ownReplacement(pod *corev1.Pod) bool {
+	owner := metav1.GetControllerOf(pod)
+	if owner == nil {
+		return false
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.prune(time.Now())
+	pending := t.replacements[owner.UID]
+	if len(pending) == 0 {
+		return false
+	}
+	if len(pending) == 1 {
+		delete(t.replacements, owner.UID)
+	} else {
+		t.replacements[owner.UID] = pending[1:]
+	}
+	return true
+}
+
+// prune drops evictions older than ownEventTTL. Callers hold the lock.
+func (t *eventTriggers) prune(now time.Time) {
+	cutoff := now.Add(-ownEventTTL)
+	for key, at := range t.evicted {
+		if at.Before(cutoff) {
+			delete(t.evicted, key)
+		}
+	}
+	for uid, pending := range t.replacements {
+		for len(pending) > 0 && pending[0].Before(cutoff) {
+			pending = pending[1:]
+		}
+		if len(pending) == 0 {
+			delete(t.replacements, uid)
+		} else {
+			t.replacements[uid] = pending
+		}
+	}
+}
+
+// nodeEventPredicate passes node events that can change where pods should run:
+// nodes being added, becoming Ready, being cordoned or uncordoned, or changing labels
+func nodeEventPredicate() predicate.Predicate {
+	return predicate.Funcs{
+		CreateFunc: func(event.CreateEvent) bool { return true },
+		DeleteFunc: func(event.DeleteEvent) bool { return false },
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldNode, ok := e.ObjectOld.(*corev1.Node)
+			if !ok {
+				return false
+			}
+			newNode, ok := e.ObjectNew.(*corev1.Node)
+			if !ok {
+				return false
+			}
+			return rebalancer.IsNodeReady(oldNode) != rebalancer.IsNodeReady(newNode) ||
+				oldNode.Spec.Unschedulable != newNode.Spec.Unschedulable ||
+				!reflect.DeepEqual(oldNode.Labels, newNode.Labels)
+		},
+		GenericFunc: func(event.GenericEvent) bool { return false },
+	}
+}
+
+// podEventPredicate passes pods being scheduled onto a node or deleted, except for pods the
+// rebalancer evicted going away and their replacements being scheduled
+func (t *eventTriggers) podEventPredicate() predicate.Predicate {
+	return predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool {
+			pod, ok := e.Object.(*corev1.Pod)
+			return ok && pod.Spec.NodeName != "" && !t.ownReplacement(pod)
+		},
+		DeleteFunc: func(e event.DeleteEvent) bool {
+			pod, ok := e.Object.(*corev1.Pod)
+			return !ok || !t.ownDeletion(pod)
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldPod, ok := e.ObjectOld.(*corev1.Pod)
+			if !ok {
+				return false
+			}
+			newPod, ok := e.ObjectNew.(*corev1.Pod)
+			if !ok {
+				return false
+			}
+			return oldPod.Spec.NodeName != newPod.Spec.NodeName && !t.ownReplacement(newPod)
+		},
+		GenericFunc: func(event.GenericEvent) bool { return false },
+	}
+}
+
+// requestsForNode enqueues every active request when a node changes
+func (r *RebalanceRequestReconciler) requestsForNode(ctx context.Context, _ client.Object) []reconcile.Request {
+	return r.triggerRequests(ctx, func(*korev1alpha1.RebalanceRequest) bool { return true })
+}
+
+// requestsForPod enqueues the active requests that manage the pod
+func (r *RebalanceRequestReconciler) requestsForPod(ctx context.Context, obj client.Object) []reconcile.Request {
+	pod, ok := obj.(*corev1.Pod)
+	if !ok {
+		return nil
+	}
+
+	var ns corev1.Namespace
+	if err := r.Get(ctx, client.ObjectKey{Name: pod.Namespace}, &ns); err != nil {
+		// The namespace default cannot be checked, so only the pod's own label counts
+		ns = corev1.Namespace{}
+	}
+	return r.triggerRequests(ctx, func(req *korev1alpha1.RebalanceRequest) bool {
+		return r.Engine.IsOptedIn(pod, &ns, req)
+	})
+}
+
+// triggerRequests records an event for the active requests accepted by match and returns them for enqueueing
+func (r *RebalanceRequestReconciler) triggerRequests(ctx context.Context, match func(*korev1alpha1.RebalanceRequest) bool) []reconcile.Request {
+	var list korev1alpha1.RebalanceRequestList
+	if err := r.List(ctx, &list); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to list RebalanceRequests for cluster event")
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for i := range list.Items {
+		req := &list.Items[i]
+		if req.Status.Phase != korev1alpha1.RebalancePhaseActive || req.Spec.Paused || !match(req) {
+			continue
+		}
+		key := client.ObjectKeyFromObject(req)
+		r.triggers.add(key)
+		requests = append(requests, reconcile.Request{NamespacedName: key})
+	}
+	return requests
+}
@@ -0,0 +1,96 @@
+package controller
+
+import (
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+func TestPodEventPredicateIgnoresOwnEvictions(t *testing.T) {
+	var triggers eventTriggers
+	evicted := testPod("evicted", "a", time.Hour)
+	other := testPod("other", "a", time.Hour)
+	triggers.evict(evicted)
+	p := triggers.podEventPredicate()
+
+	if p.Delete(event.DeleteEvent{Object: evicted}) {
+		t.Error("deletion of the evicted pod passed, want it ignored")
+	}
+	// The eviction is consumed by its deletion
+	if !p.Delete(event.DeleteEvent{Object: evicted}) {
+		t.Error("second deletion of the evicted pod was ignored")
+	}
+	if !p.Delete(event.DeleteEvent{Object: other}) {
+		t.Error("deletion of another pod was ignored")
+	}
+
+	// The controller's one expected replacement is ignored once it is scheduled
+	replacement := testPod("replacement", "", 0)
+	scheduled := replacement.DeepCopy()
+	scheduled.Spec.NodeName = "b"
+	if p.Create(event.CreateEvent{Object: replacement}) {
+		t.Error("creation of an unscheduled pod passed")
+	}
+	if p.Update(event.UpdateEvent{ObjectOld: replacement, ObjectNew: scheduled}) {
+		t.Error("scheduling of the replacement passed, want it ignored")
+	}
+	if !p.Create(event.CreateEvent{Object: testPod("scaled-up", "b", 0)}) {
+		t.Error("a second scheduled pod of the controller was ignored")
+	}
+}
+
+func TestEventTriggersReplacementsAreFIFO(t *testing.T) {
+	var triggers eventTriggers
+	triggers.evict(testPod("first", "a", time.Hour))
+	triggers.evict(testPod("second", "a", time.Hour))
+	// Age the first eviction past the TTL
+	triggers.replacements["web"][0] = time.Now().Add(-ownEventTTL - time.Minute)
+
+	if !triggers.ownReplacement(testPod("replacement-1", "b", 0)) {
+		t.Error("first replacement not recognized")
+	}
+	// The expired expectation was pruned, so only one replacement was pending
+	if triggers.ownReplacement(testPod("replacement-2", "b", 0)) {
+		t.Error("second replacement recognized, want the expired eviction pruned")
+	}
+}
+
+func TestEventTriggersPrune(t *testing.T) {
+	var triggers eventTriggers
+	triggers.evict(testPod("evicted", "a", time.Hour))
+
+	triggers.prune(time.Now().Add(ownEventTTL - time.Minute))
+	if len(triggers.evicted) != 1 || len(triggers.replacements["web"]) != 1 {
+		t.Fatalf("pruned evictions within the TTL: evicted %v, replacements %v", triggers.evicted, triggers.replacements)
+	}
+	triggers.prune(time.Now().Add(ownEventTTL + time.Minute))
+	if len(triggers.evicted) != 0 || len(triggers.replacements) != 0 {
+		t.Errorf("kept evictions past the TTL: evicted %v, replacements %v", triggers.evicted, triggers.replacements)
+	}
+}
+
+func TestEventTriggersClearKeepsLaterEvents(t *testing.T) {
+	var triggers eventTriggers
+	key := types.NamespacedName{Namespace: "default", Name: "shop"}
+	triggers.add(key)
+	first, ok := triggers.get(key)
+	if !ok {
+		t.Fatal("no pending event after add")
+	}
+	triggers.add(key)
+	if again, _ := triggers.get(key); !again.Equal(first) {
+		t.Errorf("second add moved the pending event from %v to %v", first, again)
+	}
+
+	// An event recorded after the run started survives the run's clear
+	triggers.clear(key, first.Add(-time.Second))
+	if _, ok := triggers.get(key); !ok {
+		t.Error("clear dropped an event recorded after the run started")
+	}
+	triggers.clear(key, first)
+	if _, ok := triggers.get(key); ok {
+		t.Error("clear kept an event recorded before the run")
+	}
+}
@@ -0,0 +1,352 @@
+package debug
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/yaml"
+
+	korev1alpha1 "github.com/cxfcxf/pod-rebalancer/api/v1alpha1"
+	"github.com/cxfcxf/pod-rebalancer/internal/rebalancer"
+	"github.com/cxfcxf/pod-rebalancer/internal/report"
+)
+
+// PathPrefix is the path every debug endpoint is served under
+const PathPrefix = "/debug/rebalancer/"
+
+// maxBodyBytes bounds the size of a dry-run request body
+const maxBodyBytes = 1 << 20
+
+// Server serves the manager's debug API. Every call must carry a bearer token that the API server
+// authenticates, and whose user is authorized for the request's path and lowercase HTTP method
+// as a non-resource URL.
+type Server struct {
+	// Client reads RebalanceRequests and creates the token and access reviews
+	Client client.Client
+	Engine *rebalancer.Engine
+
+	// Address is the address the server listens on
+	Address string
+
+	// CertFile and KeyFile serve TLS when set. Without them the server only binds
+	// to loopback addresses, since bearer tokens would be sent in the clear.
+	CertFile string
+	KeyFile  string
+}
+
+// RequestView is a RebalanceRequest's state as served by the debug API
+type RequestView struct {
+	Name        string                            `json:"name"`
+	Namespace   string                            `json:"namespace"`
+	Phase       korev1alpha1.RebalancePhase       `json:"phase"`
+	Paused      bool                              `json:"paused,omitempty"`
+	Message     string                            `json:"message"`
+	LastRunTime *metav1.Time                      `json:"lastRunTime,omitempty"`
+	NextRunTime *metav1.Time                      `json:"nextRunTime,omitempty"`
+	Nodes       []korev1alpha1.NodeDistribution   `json:"nodes"`
+	Skipped     []korev1alpha1.SkippedPodsSummary `json:"skipped,omitempty"`
+	Run         *RunView                          `json:"run,omitempty"`
+}
+
+// RunView is the current or last run of a request since the manager started
+type RunView struct {
+	Running   bool       `json:"running"`
+	StartTime time.Time  `json:"startTime"`
+	EndTime   *time.Time `json:"endTime,omitempty"`
+	Batch     int        `json:"batch"`
+	Batches   int        `json:"batches"`
+	Evicted   int32      `json:"evicted"`
+	Message   string     `json:"message"`
+	// Plan is the run's last computed plan
+	Plan *report.Result `json:"plan,omitempty"`
+}
+
+// DryRunResult is the plan for a posted RebalanceRequest
+type DryRunResult struct {
+	Plan      *report.Result        `json:"plan"`
+	Decisions []rebalancer.Decision `json:"decisions"`
+}
+
+// NeedLeaderElection lets every replica serve the debug API; run state is only known on the leader
+func (s *Server) NeedLeaderElection() bool {
+	return false
+}
+
+// Start serves the debug API until the context is cancelled.
+// Returns an error without serving if no TLS material is set and the address is not a loopback address.
+func (s *Server) Start(ctx context.Context) error {
+	logger := log.FromContext(ctx).WithName("debug")
+	if (s.CertFile == "") != (s.KeyFile == "") {
+		return errors.New("debug API needs both a TLS certificate and key")
+	}
+	if s.CertFile == "" && !isLoopback(s.Address) {
+		return fmt.Errorf("debug API without TLS may only bind to a loopback address, got %q", s.Address)
+	}
+
+	server := &http.Server{
+		Addr:              s.Address,
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			logger.Error(err, "Failed to shut down debug server")
+		}
+	}()
+
+	var err error
+	if s.CertFile != "" {
+		logger.Info("Serving debug API", "address", s.Address, "tls", true)
+		err = server.ListenAndServeTLS(s.CertFile, s.KeyFile)
+	} else {
+		logger.Info("Serving debug API without TLS on loopback", "address", s.Address)
+		err = server.ListenAndServe()
+	}
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
+
+// isLoopback returns true if the address only listens on a loopback interface
+func isLoopback(address string) bool {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// Handler returns the authenticated debug API:
+//
+//	GET  /debug/rebalancer/requests                               every request with its run state and plan
+//	GET  /debug/rebalancer/requests/{namespace}/{name}            one request
+//	GET  /debug/rebalancer/requests/{namespace}/{name}/explain    the decision for every managed pod, or ?pod=namespace/name
+//	POST /debug/rebalancer/dry-run                                plan a RebalanceRequest in the body against the live cache
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(PathPrefix+"requests", s.listRequests)
+	mux.HandleFunc(PathPrefix+"requests/", s.getRequest)
+	mux.HandleFunc(PathPrefix+"dry-run", s.dryRun)
+	return s.authorize(mux)
+}
+
+// authorize authenticates the bearer token with a TokenReview and checks the user may use the
+// path with a SubjectAccessReview
+func (s *Server) authorize(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || token == "" {
+			writeError(w, http.StatusUnauthorized, "a bearer token is required")
+			return
+		}
+
+		review := &authenticationv1.TokenReview{Spec: authenticationv1.TokenReviewSpec{Token: token}}
+		if err := s.Client.Create(r.Context(), review); err != nil {
+			writeError(w, http.StatusInternalServerError, fmt.Sprintf("failed to review token: %v", err))
+			return
+		}
+		if !review.Status.Authenticated {
+			writeError(w, http.StatusUnauthorized, "invalid bearer token")
+			return
+		}
+
+		user := review.Status.User
+		extra := make(map[string]authorizationv1.ExtraValue, len(user.Extra))
+		for key, value := range user.Extra {
+			extra[key] = authorizationv1.ExtraValue(value)
+		}
+		access := &authorizationv1.SubjectAccessReview{Spec: authorizationv1.SubjectAccessReviewSpec{
+			User:   user.Username,
+			UID:    user.UID,
+			Groups: user.Groups,
+			Extra:  extra,
+			NonResourceAttributes: &authorizationv1.NonResourceAttributes{
+				Path: r.URL.Path,
+				Verb: strings.ToLower(r.Method),
+			},
+		}}
+		if err := s.Client.Create(r.Context(), access); err != nil {
+			writeError(w, http.StatusInternalServerError, fmt.Sprintf("failed to review access: %v", err))
+			return
+		}
+		if !access.Status.Allowed {
+			writeError(w, http.StatusForbidden, fmt.Sprintf("user %q may not %s %s", user.Username, r.Method, r.URL.Path))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) listRequests(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "use GET")
+		return
+	}
+	var list korev1alpha1.RebalanceRequestList
+	if err := s.Client.List(r.Context(), &list); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	views := make([]RequestView, 0, len(list.Items))
+	for i := range list.Items {
+		views = append(views, s.view(&list.Items[i]))
+	}
+	writeJSON(w, http.StatusOK, views)
+}
+
+func (s *Server) getRequest(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "use GET")
+		return
+	}
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, PathPrefix+"requests/"), "/")
+	if len(parts) < 2 || len(parts) > 3 || (len(parts) == 3 && parts[2] != "explain") {
+		writeError(w, http.StatusNotFound, "expected requests/{namespace}/{name} or requests/{namespace}/{name}/explain")
+		return
+	}
+
+	var req korev1alpha1.RebalanceRequest
+	if err := s.Client.Get(r.Context(), types.NamespacedName{Namespace: parts[0], Name: parts[1]}, &req); err != nil {
+		if apierrors.IsNotFound(err) {
+			writeError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if len(parts) == 2 {
+		writeJSON(w, http.StatusOK, s.view(&req))
+		return
+	}
+
+	// Explaining plans a run, so use an engine whose load samples are separate from scheduled runs
+	engine := s.Engine.Fork()
+	if pod := r.URL.Query().Get("pod"); pod != "" {
+		namespace, name, ok := strings.Cut(pod, "/")
+		if !ok {
+			writeError(w, http.StatusBadRequest, "pod must be namespace/name")
+			return
+		}
+		decision, err := engine.Explain(r.Context(), &req, types.NamespacedName{Namespace: namespace, Name: name})
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, decision)
+		return
+	}
+	plan, err := engine.Plan(r.Context(), &req)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, plan.Decisions())
+}
+
+func (s *Server) dryRun(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "use POST")
+		return
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxBodyBytes+1))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if len(body) > maxBodyBytes {
+		writeError(w, http.StatusRequestEntityTooLarge, "the body is larger than 1MiB")
+		return
+	}
+
+	// The body is a RebalanceRequest in YAML or JSON; only its spec and status history matter
+	var req korev1alpha1.RebalanceRequest
+	if err := yaml.UnmarshalStrict(body, &req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid RebalanceRequest: %v", err))
+		return
+	}
+	if req.Name == "" {
+		req.Name = "dry-run"
+	}
+	if req.Namespace == "" {
+		req.Namespace = "default"
+	}
+
+	plan, err := s.Engine.Fork().Plan(r.Context(), &req)
+	if err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, DryRunResult{
+		Plan:      report.NewResult(&req, plan),
+		Decisions: plan.Decisions(),
+	})
+}
+
+// view combines a request's status with its run state
+func (s *Server) view(req *korev1alpha1.RebalanceRequest) RequestView {
+	view := RequestView{
+		Name:        req.Name,
+		Namespace:   req.Namespace,
+		Phase:       req.Status.Phase,
+		Paused:      req.Spec.Paused,
+		Message:     req.Status.Message,
+		LastRunTime: req.Status.LastRunTime,
+		NextRunTime: req.Status.NextRunTime,
+		Nodes:       req.Status.Nodes,
+		Skipped:     req.Status.SkippedPods,
+	}
+	state, ok := s.Engine.Runs.Get(client.ObjectKeyFromObject(req))
+	if !ok {
+		return view
+	}
+	view.Run = &RunView{
+		Running:   state.Running,
+		StartTime: state.StartTime,
+		Batch:     state.Batch,
+		Batches:   state.Batches,
+		Evicted:   state.Evicted,
+		Message:   state.Message,
+	}
+	if !state.EndTime.IsZero() {
+		view.Run.EndTime = &state.EndTime
+	}
+	if state.Plan != nil {
+		view.Run.Plan = report.NewResult(req, state.Plan)
+	}
+	return view
+}
+
+func writeJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	_ = encoder.Encode(value)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
@@ -0,0 +1,124 @@
+package rebalancer
+
+import (
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+
+	korev1alpha1 "github.com/cxfcxf/pod-rebalancer/api/v1alpha1"
+)
+
+// Guards record which circuit breaker guard halted evictions
+const (
+	GuardPendingPods               = "PendingPods"
+	GuardEvictionFailures          = "EvictionFailures"
+	GuardUnschedulableReplacements = "UnschedulableReplacements"
+	GuardNotReadyNodes             = "NotReadyNodes"
+)
+
+// defaultCircuitBreaker applies when a request does not set a circuit breaker policy
+var defaultCircuitBreaker = korev1alpha1.CircuitBreakerPolicy{
+	MaxPendingPods:          10,
+	MaxEvictionFailures:     3,
+	MaxNotReadyNodesPercent: 20,
+	CooldownSeconds:         300,
+}
+
+// circuitBreaker returns the request's circuit breaker policy, or nil if it is disabled.
+// Zero thresholds take their defaults, since requests read from files or posted to the debug
+// API never pass through CRD defaulting.
+func circuitBreaker(spec *korev1alpha1.RebalanceRequestSpec) *korev1alpha1.CircuitBreakerPolicy {
+	policy := defaultCircuitBreaker
+	if spec.CircuitBreaker == nil {
+		return &policy
+	}
+	if spec.CircuitBreaker.Disabled {
+		return nil
+	}
+	policy.MaxUnschedulableReplacements = spec.CircuitBreaker.MaxUnschedulableReplacements
+	if spec.CircuitBreaker.MaxPendingPods > 0 {
+		policy.MaxPendingPods = spec.CircuitBreaker.MaxPendingPods
+	}
+	if spec.CircuitBreaker.MaxEvictionFailures > 0 {
+		policy.MaxEvictionFailures = spec.CircuitBreaker.MaxEvictionFailures
+	}
+	if spec.CircuitBreaker.MaxNotReadyNodesPercent > 0 {
+		policy.MaxNotReadyNodesPercent = spec.CircuitBreaker.MaxNotReadyNodesPercent
+	}
+	if spec.CircuitBreaker.CooldownSeconds > 0 {
+		policy.CooldownSeconds = spec.CircuitBreaker.CooldownSeconds
+	}
+	return &policy
+}
+
+// CircuitBreakerCooldown returns how long evictions stay halted after a guard trips
+func CircuitBreakerCooldown(spec *korev1alpha1.RebalanceRequestSpec) time.Duration {
+	policy := circuitBreaker(spec)
+	if policy == nil {
+		return 0
+	}
+	return time.Duration(policy.CooldownSeconds) * time.Second
+}
+
+// pendingPod is a managed pod waiting to be scheduled or started
+type pendingPod struct {
+	pod      corev1.Pod
+	workload WorkloadRef
+}
+
+// checkGuards evaluates the guards that can be checked before evicting.
+// Returns the tripped guard and a description, or an empty guard if evictions may proceed.
+func checkGuards(req *korev1alpha1.RebalanceRequest, readyNodes, notReadyNodes int, candidates *candidateSet) (string, string) {
+	policy := circuitBreaker(&req.Spec)
+	if policy == nil {
+		return "", ""
+	}
+
+	if total := readyNodes + notReadyNodes; total > 0 {
+		percent := notReadyNodes * 100 / total
+		if percent > int(policy.MaxNotReadyNodesPercent) {
+			return GuardNotReadyNodes, fmt.Sprintf("%d of %d nodes are NotReady", notReadyNodes, total)
+		}
+	}
+
+	if len(candidates.pending) > int(policy.MaxPendingPods) {
+		return GuardPendingPods, fmt.Sprintf("%d managed pods are Pending", len(candidates.pending))
+	}
+
+	// Replacements of recently evicted workloads that the scheduler cannot place
+	cutoff := time.Now().Add(-EvictionWindow(&req.Spec))
+	evicted := make(map[string]bool)
+	for _, record := range req.Status.RecentEvictions {
+		if !record.Time.Time.Before(cutoff) {
+			evicted[record.Workload] = true
+		}
+	}
+	unschedulable := 0
+	for _, pending := range candidates.pending {
+		if evicted[pending.workload.String()] && isPodUnschedulable(&pending.pod) {
+			unschedulable++
+		}
+	}
+	if unschedulable > int(policy.MaxUnschedulableReplacements) {
+		return GuardUnschedulableReplacements, fmt.Sprintf("%d replacement pods cannot be scheduled", unschedulable)
+	}
+
+	return "", ""
+}
+
+// evictionFailuresExceeded checks if a run's failed evictions trip the breaker
+func evictionFailuresExceeded(spec *korev1alpha1.RebalanceRequestSpec, failures int) bool {
+	policy := circuitBreaker(spec)
+	return policy != nil && failures > int(policy.MaxEvictionFailures)
+}
+
+// isPodUnschedulable checks if the scheduler reported that the pod does not fit any node
+func isPodUnschedulable(pod *corev1.Pod) bool {
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type == corev1.PodScheduled {
+			return cond.Status == corev1.ConditionFalse && cond.Reason == corev1.PodReasonUnschedulable
+		}
+	}
+	return false
+}
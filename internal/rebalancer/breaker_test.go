@@ -0,0 +1,113 @@
+package rebalancer
+
+import (
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	korev1alpha1 "github.com/cxfcxf/pod-rebalancer/api/v1alpha1"
+)
+
+func TestCircuitBreakerFillsZeroThresholds(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy *korev1alpha1.CircuitBreakerPolicy
+		want   *korev1alpha1.CircuitBreakerPolicy
+	}{
+		{name: "unset", policy: nil, want: &defaultCircuitBreaker},
+		{name: "empty", policy: &korev1alpha1.CircuitBreakerPolicy{}, want: &defaultCircuitBreaker},
+		{name: "disabled", policy: &korev1alpha1.CircuitBreakerPolicy{Disabled: true}, want: nil},
+		{
+			name:   "partial",
+			policy: &korev1alpha1.CircuitBreakerPolicy{MaxPendingPods: 2, MaxUnschedulableReplacements: 1},
+			want: &korev1alpha1.CircuitBreakerPolicy{
+				MaxPendingPods:               2,
+				MaxEvictionFailures:          defaultCircuitBreaker.MaxEvictionFailures,
+				MaxUnschedulableReplacements: 1,
+				MaxNotReadyNodesPercent:      defaultCircuitBreaker.MaxNotReadyNodesPercent,
+				CooldownSeconds:              defaultCircuitBreaker.CooldownSeconds,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := circuitBreaker(&korev1alpha1.RebalanceRequestSpec{CircuitBreaker: tt.policy})
+			if (got == nil) != (tt.want == nil) || (got != nil && *got != *tt.want) {
+				t.Errorf("circuitBreaker() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+// unschedulablePod returns a pending pod the scheduler could not place
+func unschedulablePod(name string, workload WorkloadRef) pendingPod {
+	pod := testPod(name, "", 0)
+	pod.Status.Phase = corev1.PodPending
+	pod.Status.Conditions = []corev1.PodCondition{{
+		Type:   corev1.PodScheduled,
+		Status: corev1.ConditionFalse,
+		Reason: corev1.PodReasonUnschedulable,
+	}}
+	return pendingPod{pod: *pod, workload: workload}
+}
+
+func TestCheckGuards(t *testing.T) {
+	web := WorkloadRef{Kind: "Deployment", Namespace: "shop", Name: "web"}
+	pending := func(n int, workload WorkloadRef) []pendingPod {
+		pods := make([]pendingPod, n)
+		for i := range pods {
+			pods[i] = unschedulablePod("pending", workload)
+		}
+		return pods
+	}
+	recent := []korev1alpha1.EvictionRecord{{
+		Pod: "shop/web-1", Workload: web.String(), Node: "a", Time: metav1.NewTime(time.Now().Add(-time.Minute)),
+	}}
+
+	tests := []struct {
+		name            string
+		policy          *korev1alpha1.CircuitBreakerPolicy
+		ready, notReady int
+		pending         []pendingPod
+		recent          []korev1alpha1.EvictionRecord
+		want            string
+	}{
+		{name: "healthy", ready: 10, pending: pending(10, WorkloadRef{})},
+		// An empty policy takes the defaults, so one Pending pod does not trip it
+		{name: "empty policy", policy: &korev1alpha1.CircuitBreakerPolicy{}, ready: 10, pending: pending(1, WorkloadRef{})},
+		{name: "pending pods", ready: 10, pending: pending(11, WorkloadRef{}), want: GuardPendingPods},
+		{name: "not ready nodes at threshold", ready: 8, notReady: 2},
+		{name: "not ready nodes", ready: 7, notReady: 3, want: GuardNotReadyNodes},
+		{name: "unschedulable replacement", ready: 10, pending: pending(1, web), recent: recent, want: GuardUnschedulableReplacements},
+		{name: "unschedulable without eviction", ready: 10, pending: pending(1, web)},
+		{name: "disabled", policy: &korev1alpha1.CircuitBreakerPolicy{Disabled: true}, ready: 1, notReady: 9, pending: pending(50, web), recent: recent},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := &korev1alpha1.RebalanceRequest{
+				Spec:   korev1alpha1.RebalanceRequestSpec{CircuitBreaker: tt.policy},
+				Status: korev1alpha1.RebalanceRequestStatus{RecentEvictions: tt.recent},
+			}
+			guard, _ := checkGuards(req, tt.ready, tt.notReady, &candidateSet{pending: tt.pending})
+			if guard != tt.want {
+				t.Errorf("checkGuards() = %q, want %q", guard, tt.want)
+			}
+		})
+	}
+}
+
+func TestEvictionFailuresExceeded(t *testing.T) {
+	spec := &korev1alpha1.RebalanceRequestSpec{}
+	if evictionFailuresExceeded(spec, 3) {
+		t.Error("3 failures tripped the default limit of 3")
+	}
+	if !evictionFailuresExceeded(spec, 4) {
+		t.Error("4 failures did not trip the default limit of 3")
+	}
+	spec.CircuitBreaker = &korev1alpha1.CircuitBreakerPolicy{Disabled: true}
+	if evictionFailuresExceeded(spec, 100) {
+		t.Error("a disabled breaker tripped")
+	}
+}
@@ -0,0 +1,92 @@
+package rebalancer
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// DisruptionBudget paces evictions across all RebalanceRequests with shared token buckets,
+// one for the whole cluster and one per namespace
+type DisruptionBudget struct {
+	cluster *rate.Limiter
+
+	namespaceLimit rate.Limit
+	burst          int
+	mu             sync.Mutex
+	namespaces     map[string]*rate.Limiter
+}
+
+// NewDisruptionBudget creates a budget allowing the given number of evictions per minute
+// cluster-wide and per namespace. Zero disables the respective limit. burst is how many
+// evictions may happen back to back before pacing applies.
+func NewDisruptionBudget(clusterPerMinute, namespacePerMinute, burst int) *DisruptionBudget {
+	if burst < 1 {
+		burst = 1
+	}
+	b := &DisruptionBudget{
+		burst:      burst,
+		namespaces: make(map[string]*rate.Limiter),
+	}
+	if clusterPerMinute > 0 {
+		b.cluster = rate.NewLimiter(perMinute(clusterPerMinute), burst)
+	}
+	if namespacePerMinute > 0 {
+		b.namespaceLimit = perMinute(namespacePerMinute)
+	}
+	return b
+}
+
+// Wait blocks until an eviction in the namespace fits the budget.
+// If the context ends first, the reserved tokens are returned and the context's error is returned.
+func (b *DisruptionBudget) Wait(ctx context.Context, namespace string) error {
+	var reservations []*rate.Reservation
+	var delay time.Duration
+	for _, limiter := range b.limiters(namespace) {
+		reservation := limiter.Reserve()
+		reservations = append(reservations, reservation)
+		if d := reservation.Delay(); d > delay {
+			delay = d
+		}
+	}
+	if delay == 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		for _, reservation := range reservations {
+			reservation.Cancel()
+		}
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// limiters returns the buckets an eviction in the namespace draws from
+func (b *DisruptionBudget) limiters(namespace string) []*rate.Limiter {
+	var limiters []*rate.Limiter
+	if b.cluster != nil {
+		limiters = append(limiters, b.cluster)
+	}
+	if b.namespaceLimit > 0 {
+		b.mu.Lock()
+		limiter, ok := b.namespaces[namespace]
+		if !ok {
+			limiter = rate.NewLimiter(b.namespaceLimit, b.burst)
+			b.namespaces[namespace] = limiter
+		}
+		b.mu.Unlock()
+		limiters = append(limiters, limiter)
+	}
+	return limiters
+}
+
+func perMinute(n int) rate.Limit {
+	return rate.Limit(float64(n) / 60)
+}
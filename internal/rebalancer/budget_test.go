@@ -0,0 +1,64 @@
+package rebalancer
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// waitNow calls Wait with a short deadline, so a paced eviction fails instead of blocking the test
+func waitNow(t *testing.T, b *DisruptionBudget, namespace string) error {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	return b.Wait(ctx, namespace)
+}
+
+func TestDisruptionBudgetWait(t *testing.T) {
+	tests := []struct {
+		name               string
+		cluster, ns, burst int
+		namespaces         []string
+		wantPaced          []bool
+	}{
+		{name: "disabled", namespaces: []string{"shop", "shop", "shop"}, wantPaced: []bool{false, false, false}},
+		{name: "cluster burst", cluster: 1, burst: 2, namespaces: []string{"shop", "blog", "shop"}, wantPaced: []bool{false, false, true}},
+		{name: "per namespace", ns: 1, namespaces: []string{"shop", "blog", "shop"}, wantPaced: []bool{false, false, true}},
+		{name: "cluster before namespace", cluster: 1, ns: 10, namespaces: []string{"shop", "blog"}, wantPaced: []bool{false, true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := NewDisruptionBudget(tt.cluster, tt.ns, tt.burst)
+			for i, namespace := range tt.namespaces {
+				err := waitNow(t, b, namespace)
+				if paced := errors.Is(err, context.DeadlineExceeded); paced != tt.wantPaced[i] {
+					t.Errorf("eviction %d in %s: Wait() error = %v, want paced %v", i+1, namespace, err, tt.wantPaced[i])
+				}
+			}
+		})
+	}
+}
+
+func TestDisruptionBudgetWaitCancelReturnsTokens(t *testing.T) {
+	b := NewDisruptionBudget(1, 1, 1)
+	if err := waitNow(t, b, "shop"); err != nil {
+		t.Fatalf("first Wait() error = %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- b.Wait(ctx, "shop") }()
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Fatalf("Wait() error = %v, want context.Canceled", err)
+	}
+
+	// Without the returned reservations both buckets would be a token in debt
+	for _, limiter := range b.limiters("shop") {
+		if tokens := limiter.Tokens(); tokens < -0.1 {
+			t.Errorf("bucket holds %.2f tokens after the cancelled wait, want about 0", tokens)
+		}
+	}
+}
@@ -2,88 +2,362 @@ package rebalancer
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sort"
 	"time"
 
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	corev1 "k8s.io/api/core/v1"
 	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
 	korev1alpha1 "github.com/cxfcxf/pod-rebalancer/api/v1alpha1"
+	"github.com/cxfcxf/pod-rebalancer/internal/tracing"
 )
 
 const (
-	// RebalanceEnabledLabel is the label that must be present on pods to be considered for rebalancing
+	// RebalanceEnabledLabel is the default label that must be present on pods to be considered for rebalancing
 	RebalanceEnabledLabel = "kore.boring.io/rebalance"
+
+	// RebalanceExcludeAnnotation opts a pod out of rebalancing when set to "true"
+	RebalanceExcludeAnnotation = "kore.boring.io/rebalance-exclude"
+
+	// NamespaceDefaultAnnotation opts in every pod of a namespace that does not set the opt-in label itself
+	NamespaceDefaultAnnotation = "kore.boring.io/rebalance-default"
+
+	// AllowLocalStorageEvictionLabel allows evicting a pod that uses local storage when set to "true"
+	AllowLocalStorageEvictionLabel = "kore.boring.io/allow-local-storage-eviction"
+
+	// RunNowAnnotation on a RebalanceRequest triggers an immediate run once per distinct value,
+	// such as a timestamp
+	RunNowAnnotation = "kore.boring.io/run-now"
+
+	// PodNodeNameField is the field index on the node a pod is scheduled to.
+	// The API server supports the same field selector, so it works with cached and uncached readers.
+	PodNodeNameField = "spec.nodeName"
+
+	// unmanagedPodsPageSize bounds how many pods are read per request when counting unmanaged pods
+	unmanagedPodsPageSize = 500
 )
 
+// tracer records the spans of planning and evicting
+var tracer = otel.Tracer("github.com/cxfcxf/pod-rebalancer/internal/rebalancer")
+
+// DefaultExcludedNamespaces are skipped when a request does not set ExcludedNamespaces
+var DefaultExcludedNamespaces = []string{"kube-system", "kube-public", "kube-node-lease"}
+
 // Engine handles the core rebalancing logic
 type Engine struct {
 	Client client.Client
+
+	// Reader reads pods outside the manager's cache, which only holds pods carrying OptInLabel.
+	// Usually the manager's API reader; defaults to Client.
+	Reader client.Reader
+
+	// OptInLabel is the pod label used when a request does not set its own
+	OptInLabel string
+
+	// LoadProviders measure load for the Load strategy, by source.
+	// Requests whose source has no provider fall back to pod count balancing.
+	LoadProviders map[korev1alpha1.LoadSource]LoadProvider
+
+	// Budget paces evictions across all requests. Nil means no shared limit.
+	Budget *DisruptionBudget
+
+	// Runs records the progress of each run. Nil records nothing.
+	Runs *RunTracker
+
+	// OnEvict, if set, is called with each pod as soon as it has been evicted
+	OnEvict func(pod *corev1.Pod)
+
+	samples loadSamples
 }
 
 // NewEngine creates a new rebalancer engine
 func NewEngine(c client.Client) *Engine {
-	return &Engine{Client: c}
+	return &Engine{Client: c, OptInLabel: RebalanceEnabledLabel}
+}
+
+// Fork returns an engine sharing the clients and load providers but with its own load samples,
+// so ad-hoc plans do not disturb the smoothing of scheduled runs
+func (e *Engine) Fork() *Engine {
+	return &Engine{
+		Client:        e.Client,
+		Reader:        e.Reader,
+		OptInLabel:    e.OptInLabel,
+		LoadProviders: e.LoadProviders,
+	}
 }
 
+// IndexPodNodeName registers the PodNodeNameField index with the manager's cache
+func IndexPodNodeName(ctx context.Context, indexer client.FieldIndexer) error {
+	return indexer.IndexField(ctx, &corev1.Pod{}, PodNodeNameField, PodNodeName)
+}
+
+// PodNodeName extracts the PodNodeNameField index value from a pod
+func PodNodeName(obj client.Object) []string {
+	pod, ok := obj.(*corev1.Pod)
+	if !ok || pod.Spec.NodeName == "" {
+		return nil
+	}
+	return []string{pod.Spec.NodeName}
+}
+
+// reader returns the reader for pods that may not be in the manager's cache
+func (e *Engine) reader() client.Reader {
+	if e.Reader != nil {
+		return e.Reader
+	}
+	return e.Client
+}
+
+// Target sources record which rule produced a node's target
+const (
+	TargetSourceAverage      = "Average"
+	TargetSourceProportional = "Proportional"
+	TargetSourceHardCap      = "HardCap"
+)
+
 // NodePodCount represents a node and its pod count for balancing decisions
 type NodePodCount struct {
-	NodeName   string
-	Node       *corev1.Node
-	PodCount   int
-	MaxPods    int // Maximum pods allowed (-1 means no limit, use average)
-	Pods       []corev1.Pod
+	NodeName     string
+	Node         *corev1.Node
+	PodCount     int
+	MaxPods      int // Maximum pods allowed (-1 means no limit, use average)
+	Capacity     int // Effective capacity after capacity accounting
+	Unmanaged    int // Pods on the node the rebalancer does not manage
+	Pressure     bool
+	Utilization  float64 // Smoothed utilisation when balancing by load
+	Target       int     // Pods above this count are evicted
+	TargetSource string
+	Pods         []corev1.Pod
+}
+
+// SkipReason explains why a managed pod was not considered for eviction
+type SkipReason string
+
+const (
+	SkipReasonNotRunning            SkipReason = "NotRunning"
+	SkipReasonDaemonSet             SkipReason = "DaemonSet"
+	SkipReasonOrphan                SkipReason = "Orphan"
+	SkipReasonWorkloadPolicy        SkipReason = "WorkloadPolicy"
+	SkipReasonMemoryEmptyDir        SkipReason = "MemoryEmptyDir"
+	SkipReasonEmptyDir              SkipReason = "EmptyDir"
+	SkipReasonHostPath              SkipReason = "HostPath"
+	SkipReasonLocalPersistentVolume SkipReason = "LocalPersistentVolume"
+)
+
+// SkippedPod records a managed pod that was not considered for eviction
+type SkippedPod struct {
+	Namespace string
+	Name      string
+	Reason    SkipReason
+}
+
+// candidateSet holds the pods eligible for rebalancing and the managed pods that were skipped
+type candidateSet struct {
+	pods      []corev1.Pod
+	skipped   []SkippedPod
+	workloads map[types.NamespacedName]WorkloadRef
+	serial    map[WorkloadRef]bool
+	// notReady holds the Serial workloads still replacing a pod, which count on their nodes
+	// but give no victims
+	notReady map[WorkloadRef]bool
+	pending  []pendingPod
 }
 
 // RebalanceResult contains the result of a rebalance operation
 type RebalanceResult struct {
 	PodsEvicted int32
-	TotalPods   int32
-	Error       error
-	Message     string
+	// FailedEvictions counts the evictions the API server refused or that errored
+	FailedEvictions int32
+	TotalPods       int32
+	Nodes           []NodePodCount
+	Skipped         []SkippedPod
+	Evictions       []Eviction
+	Plan            *Plan  // What the run decided to evict
+	Tripped         string // Circuit breaker guard that halted evictions
+	Error           error
+	Message         string
 }
 
-// ExecuteRebalance performs the rebalancing operation based on the RebalanceRequest spec
-func (e *Engine) ExecuteRebalance(ctx context.Context, req *korev1alpha1.RebalanceRequest) RebalanceResult {
+// Plan is what a rebalance run would evict, computed without evicting anything
+type Plan struct {
+	TotalPods int
+	Nodes     []NodePodCount
+	Skipped   []SkippedPod
+	Victims   []corev1.Pod
+	Workloads map[types.NamespacedName]WorkloadRef // Top-level owner of each candidate pod
+	ByLoad    bool                                 // Targets come from measured load
+	Tripped   string                               // Circuit breaker guard that halted evictions
+	Message   string                               // Explains a plan without victims
+
+	// candidates and the pods victim selection offered and held back, for Decisions
+	candidates    []corev1.Pod
+	offered, held map[types.NamespacedName]bool
+}
+
+// Plan determines which pods a rebalance run would evict. It reads the cluster but changes nothing.
+// On error the plan holds whatever was determined so far, such as the skipped pods.
+func (e *Engine) Plan(ctx context.Context, req *korev1alpha1.RebalanceRequest) (plan *Plan, err error) {
+	ctx, span := tracer.Start(ctx, "Plan", trace.WithAttributes(tracing.Request(client.ObjectKeyFromObject(req))))
+	defer func() {
+		span.SetAttributes(attribute.Int("rebalancer.victims", len(plan.Victims)))
+		if plan.Message != "" {
+			span.SetAttributes(tracing.Result(plan.Message))
+		}
+		tracing.End(span, err)
+	}()
+
 	logger := log.FromContext(ctx)
+	plan = &Plan{}
 
 	// Get all ready nodes
-	nodes, err := e.getReadyNodes(ctx)
+	nodes, notReady, err := e.getReadyNodes(ctx)
 	if err != nil {
-		return RebalanceResult{Error: fmt.Errorf("failed to get nodes: %w", err)}
+		return plan, fmt.Errorf("failed to get nodes: %w", err)
 	}
 
 	if len(nodes) < 1 {
-		return RebalanceResult{Message: "No ready nodes found"}
+		plan.Message = "No ready nodes found"
+		return plan, nil
 	}
 
 	// Get pods that are candidates for rebalancing
-	pods, err := e.getCandidatePods(ctx, req)
+	listCtx, listSpan := tracer.Start(ctx, "ListCandidates")
+	candidates, err := e.getCandidatePods(listCtx, req)
+	if err == nil {
+		listSpan.SetAttributes(
+			attribute.Int("rebalancer.candidates", len(candidates.pods)),
+			attribute.Int("rebalancer.skipped", len(candidates.skipped)),
+		)
+	}
+	tracing.End(listSpan, err)
 	if err != nil {
-		return RebalanceResult{Error: fmt.Errorf("failed to get candidate pods: %w", err)}
+		return plan, fmt.Errorf("failed to get candidate pods: %w", err)
+	}
+	pods := candidates.pods
+	plan.TotalPods = len(pods)
+	plan.Skipped = candidates.skipped
+	plan.Workloads = candidates.workloads
+	plan.candidates = candidates.pods
+
+	// Halt before evicting anything while the cluster looks unhealthy
+	if guard, message := checkGuards(req, len(nodes), notReady, candidates); guard != "" {
+		logger.Info("Circuit breaker tripped", "guard", guard, "reason", message)
+		plan.Tripped = guard
+		plan.Message = "Circuit breaker tripped: " + message
+		return plan, nil
 	}
 
 	if len(pods) == 0 {
-		return RebalanceResult{Message: "No pods found matching criteria"}
+		plan.Message = "No pods found matching criteria"
+		return plan, nil
 	}
 
-	// Calculate which pods exceed their node's maximum
-	podsToEvict := e.calculatePodsToEvict(nodes, pods, req.Spec.NodeTargets)
+	// Only balance across nodes the managed pods are allowed to run on
+	nodes, err = e.getEligibleNodes(nodes, candidates, req)
+	if err != nil {
+		return plan, fmt.Errorf("failed to filter nodes: %w", err)
+	}
 
-	if len(podsToEvict) == 0 {
+	if len(nodes) < 1 {
+		plan.Message = "No eligible nodes found"
+		return plan, nil
+	}
+
+	// Count pods the rebalancer does not manage when they reduce capacity
+	var unmanaged map[string]int
+	if req.Spec.CapacityAccounting != nil && req.Spec.CapacityAccounting.UnmanagedPods {
+		unmanaged, err = e.countUnmanagedPods(ctx, nodes, candidates)
+		if err != nil {
+			return plan, fmt.Errorf("failed to count unmanaged pods: %w", err)
+		}
+	}
+
+	// Calculate which pods to evict, by measured load if requested and available
+	calcCtx, calcSpan := tracer.Start(ctx, "CalculateTargets", trace.WithAttributes(attribute.Int("rebalancer.nodes", len(nodes))))
+	if req.Spec.Strategy == korev1alpha1.BalanceStrategyLoad {
+		plan.Nodes, plan.Victims, err = e.calculateLoadPodsToEvict(calcCtx, client.ObjectKeyFromObject(req), nodes, pods, &req.Spec, plan.trackSelection(newEvictionGuard(req, candidates)))
+		switch {
+		case errors.Is(err, ErrLoadUnavailable):
+			logger.Info("Load unavailable, falling back to pod count balancing", "reason", err.Error())
+		case err != nil:
+			tracing.End(calcSpan, err)
+			return plan, fmt.Errorf("failed to calculate load: %w", err)
+		default:
+			plan.ByLoad = true
+		}
+	}
+	if !plan.ByLoad {
+		// Calculate which pods exceed their node's maximum
+		plan.Nodes, plan.Victims, err = e.calculatePodsToEvict(nodes, pods, unmanaged, &req.Spec, plan.trackSelection(newEvictionGuard(req, candidates)))
+		if err != nil {
+			tracing.End(calcSpan, err)
+			return plan, fmt.Errorf("failed to calculate targets: %w", err)
+		}
+	}
+	calcSpan.SetAttributes(attribute.Bool("rebalancer.by_load", plan.ByLoad), attribute.Int("rebalancer.victims", len(plan.Victims)))
+	calcSpan.End()
+
+	if len(plan.Victims) == 0 {
+		plan.Message = "All nodes within limits"
+		if plan.ByLoad {
+			plan.Message = "All nodes within load threshold"
+		}
+	}
+	return plan, nil
+}
+
+// ExecuteRebalance performs the rebalancing operation based on the RebalanceRequest spec
+func (e *Engine) ExecuteRebalance(ctx context.Context, req *korev1alpha1.RebalanceRequest) RebalanceResult {
+	key := client.ObjectKeyFromObject(req)
+	ctx, span := tracer.Start(ctx, "ExecuteRebalance", trace.WithAttributes(
+		tracing.Request(key),
+		attribute.Bool("rebalancer.dry_run", req.Spec.DryRun),
+	))
+	e.Runs.begin(key)
+	result := e.executeRebalance(ctx, req, key)
+	e.Runs.end(key, result)
+
+	span.SetAttributes(tracing.Result(result.Message), attribute.Int("rebalancer.pods_evicted", int(result.PodsEvicted)))
+	if result.Tripped != "" {
+		span.SetAttributes(attribute.String("rebalancer.tripped", result.Tripped))
+	}
+	tracing.End(span, result.Error)
+	return result
+}
+
+func (e *Engine) executeRebalance(ctx context.Context, req *korev1alpha1.RebalanceRequest, key types.NamespacedName) RebalanceResult {
+	logger := log.FromContext(ctx)
+
+	plan, err := e.Plan(ctx, req)
+	if err != nil {
+		return RebalanceResult{Skipped: plan.Skipped, Plan: plan, Error: err}
+	}
+	if len(plan.Victims) == 0 {
 		return RebalanceResult{
-			TotalPods: int32(len(pods)),
-			Message:   "All nodes within limits",
+			TotalPods: int32(plan.TotalPods),
+			Nodes:     plan.Nodes,
+			Skipped:   plan.Skipped,
+			Plan:      plan,
+			Tripped:   plan.Tripped,
+			Message:   plan.Message,
 		}
 	}
+	totalPods, nodeCounts, podsToEvict := int32(plan.TotalPods), plan.Nodes, plan.Victims
 
 	logger.Info("Found pods exceeding node limits",
-		"totalCandidatePods", len(pods),
+		"totalCandidatePods", totalPods,
 		"podsToEvict", len(podsToEvict),
 		"dryRun", req.Spec.DryRun,
 	)
@@ -99,27 +373,85 @@ func (e *Engine) ExecuteRebalance(ctx context.Context, req *korev1alpha1.Rebalan
 	}
 
 	var evicted int32
+	var evictions []Eviction
+	failures := 0
+	e.Runs.planned(key, plan, (len(podsToEvict)+batchSize-1)/batchSize)
 	for i := 0; i < len(podsToEvict); i += batchSize {
 		end := i + batchSize
 		if end > len(podsToEvict) {
 			end = len(podsToEvict)
 		}
 		batch := podsToEvict[i:end]
+		e.Runs.progress(key, i/batchSize+1, evicted)
+		batchEvicted := evicted
+		batchCtx, batchSpan := tracer.Start(ctx, "EvictBatch", trace.WithAttributes(
+			tracing.BatchKey.Int(i/batchSize+1),
+			attribute.Int("rebalancer.batch_size", len(batch)),
+		))
 
 		for _, pod := range batch {
 			if req.Spec.DryRun {
 				logger.Info("DryRun: would evict pod", "pod", pod.Name, "namespace", pod.Namespace, "node", pod.Spec.NodeName)
+				batchSpan.AddEvent("DryRun", trace.WithAttributes(tracing.Pod(&pod)...))
 				evicted++
 				continue
 			}
 
-			if err := e.evictPod(ctx, &pod); err != nil {
+			// Wait for the shared disruption budget before evicting
+			if e.Budget != nil {
+				if err := e.Budget.Wait(batchCtx, pod.Namespace); err != nil {
+					tracing.End(batchSpan, err)
+					return RebalanceResult{
+						PodsEvicted:     evicted,
+						FailedEvictions: int32(failures),
+						TotalPods:       totalPods,
+						Nodes:           nodeCounts,
+						Skipped:         plan.Skipped,
+						Plan:            plan,
+						Evictions:       evictions,
+						Error:           err,
+						Message:         "Rebalance interrupted",
+					}
+				}
+			}
+
+			if err := e.evictPod(batchCtx, &pod); err != nil {
 				logger.Error(err, "Failed to evict pod", "pod", pod.Name, "namespace", pod.Namespace)
+				failures++
+				if evictionFailuresExceeded(&req.Spec, failures) {
+					logger.Info("Circuit breaker tripped", "guard", GuardEvictionFailures, "failures", failures)
+					batchSpan.SetAttributes(tracing.Result("Circuit breaker tripped"))
+					batchSpan.End()
+					return RebalanceResult{
+						PodsEvicted:     evicted,
+						FailedEvictions: int32(failures),
+						TotalPods:       totalPods,
+						Nodes:           nodeCounts,
+						Skipped:         plan.Skipped,
+						Plan:            plan,
+						Evictions:       evictions,
+						Tripped:         GuardEvictionFailures,
+						Message:         fmt.Sprintf("Circuit breaker tripped: %d evictions failed", failures),
+					}
+				}
 				continue
 			}
 			evicted++
+			if e.OnEvict != nil {
+				e.OnEvict(&pod)
+			}
+			e.Runs.progress(key, i/batchSize+1, evicted)
+			evictions = append(evictions, Eviction{
+				Namespace: pod.Namespace,
+				Name:      pod.Name,
+				Workload:  plan.Workloads[client.ObjectKeyFromObject(&pod)],
+				Node:      pod.Spec.NodeName,
+				Time:      time.Now(),
+			})
 			logger.Info("Evicted pod", "pod", pod.Name, "namespace", pod.Namespace, "node", pod.Spec.NodeName)
 		}
+		batchSpan.SetAttributes(attribute.Int("rebalancer.pods_evicted", int(evicted-batchEvicted)))
+		batchSpan.End()
 
 		// Wait between batches (except for the last batch)
 		if end < len(podsToEvict) {
@@ -127,10 +459,15 @@ func (e *Engine) ExecuteRebalance(ctx context.Context, req *korev1alpha1.Rebalan
 			select {
 			case <-ctx.Done():
 				return RebalanceResult{
-					PodsEvicted: evicted,
-					TotalPods:   int32(len(pods)),
-					Error:       ctx.Err(),
-					Message:     "Rebalance interrupted",
+					PodsEvicted:     evicted,
+					FailedEvictions: int32(failures),
+					TotalPods:       totalPods,
+					Nodes:           nodeCounts,
+					Skipped:         plan.Skipped,
+					Plan:            plan,
+					Evictions:       evictions,
+					Error:           ctx.Err(),
+					Message:         "Rebalance interrupted",
 				}
 			case <-time.After(batchInterval):
 			}
@@ -138,105 +475,372 @@ func (e *Engine) ExecuteRebalance(ctx context.Context, req *korev1alpha1.Rebalan
 	}
 
 	return RebalanceResult{
-		PodsEvicted: evicted,
-		TotalPods:   int32(len(pods)),
-		Message:     fmt.Sprintf("Evicted %d pods exceeding limits", evicted),
+		PodsEvicted:     evicted,
+		FailedEvictions: int32(failures),
+		TotalPods:       totalPods,
+		Nodes:           nodeCounts,
+		Skipped:         plan.Skipped,
+		Plan:            plan,
+		Evictions:       evictions,
+		Message:         fmt.Sprintf("Evicted %d pods exceeding limits", evicted),
 	}
 }
 
-// getReadyNodes returns all nodes that are Ready and schedulable
-func (e *Engine) getReadyNodes(ctx context.Context) ([]corev1.Node, error) {
+// getReadyNodes returns all nodes that are Ready and schedulable, along with the number of NotReady nodes
+func (e *Engine) getReadyNodes(ctx context.Context) ([]corev1.Node, int, error) {
 	var nodeList corev1.NodeList
 	if err := e.Client.List(ctx, &nodeList); err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 
 	var readyNodes []corev1.Node
+	notReady := 0
 	for _, node := range nodeList.Items {
-		if isNodeReady(&node) && !isNodeUnschedulable(&node) {
+		if !IsNodeReady(&node) {
+			notReady++
+			continue
+		}
+		if !isNodeUnschedulable(&node) {
 			readyNodes = append(readyNodes, node)
 		}
 	}
-	return readyNodes, nil
+	return readyNodes, notReady, nil
+}
+
+// countUnmanagedPods counts the non-terminal pods on each node that are not rebalancing candidates.
+// Unmanaged pods are not cached, so each eligible node's pods are listed by field selector,
+// a page at a time so a crowded node never has to be held in memory at once.
+func (e *Engine) countUnmanagedPods(ctx context.Context, nodes []corev1.Node, candidates *candidateSet) (map[string]int, error) {
+	counts := make(map[string]int, len(nodes))
+	for _, node := range nodes {
+		page := ""
+		for {
+			var podList corev1.PodList
+			if err := e.reader().List(ctx, &podList, client.MatchingFields{PodNodeNameField: node.Name},
+				client.Limit(unmanagedPodsPageSize), client.Continue(page)); err != nil {
+				return nil, err
+			}
+
+			for i := range podList.Items {
+				pod := &podList.Items[i]
+				if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+					continue
+				}
+				if _, ok := candidates.workloads[client.ObjectKeyFromObject(pod)]; ok {
+					continue
+				}
+				counts[node.Name]++
+			}
+
+			if page = podList.Continue; page == "" {
+				break
+			}
+		}
+	}
+	return counts, nil
+}
+
+// getEligibleNodes filters nodes by the request's node selectors and drops nodes
+// with NoSchedule or NoExecute taints that none of the candidate workloads tolerate
+func (e *Engine) getEligibleNodes(nodes []corev1.Node, candidates *candidateSet, req *korev1alpha1.RebalanceRequest) ([]corev1.Node, error) {
+	var include, exclude labels.Selector
+	var err error
+	if req.Spec.NodeSelector != nil {
+		if include, err = metav1.LabelSelectorAsSelector(req.Spec.NodeSelector); err != nil {
+			return nil, fmt.Errorf("invalid node selector: %w", err)
+		}
+	}
+	if req.Spec.ExcludeNodeSelector != nil {
+		if exclude, err = metav1.LabelSelectorAsSelector(req.Spec.ExcludeNodeSelector); err != nil {
+			return nil, fmt.Errorf("invalid exclude node selector: %w", err)
+		}
+	}
+
+	// One pod per workload is enough to know which taints the workload tolerates
+	var tolerations [][]corev1.Toleration
+	seen := make(map[WorkloadRef]bool)
+	for i := range candidates.pods {
+		pod := &candidates.pods[i]
+		workload := candidates.workloads[client.ObjectKeyFromObject(pod)]
+		if seen[workload] {
+			continue
+		}
+		seen[workload] = true
+		tolerations = append(tolerations, pod.Spec.Tolerations)
+	}
+
+	var eligible []corev1.Node
+	for _, node := range nodes {
+		nodeLabels := labels.Set(node.Labels)
+		if include != nil && !include.Matches(nodeLabels) {
+			continue
+		}
+		if exclude != nil && !exclude.Empty() && exclude.Matches(nodeLabels) {
+			continue
+		}
+
+		for _, podTolerations := range tolerations {
+			if ToleratesNodeTaints(&node, podTolerations) {
+				eligible = append(eligible, node)
+				break
+			}
+		}
+	}
+	return eligible, nil
 }
 
 // getCandidatePods returns pods that are candidates for rebalancing
-func (e *Engine) getCandidatePods(ctx context.Context, req *korev1alpha1.RebalanceRequest) ([]corev1.Pod, error) {
-	var allPods []corev1.Pod
+func (e *Engine) getCandidatePods(ctx context.Context, req *korev1alpha1.RebalanceRequest) (*candidateSet, error) {
+	candidates := &candidateSet{
+		workloads: make(map[types.NamespacedName]WorkloadRef),
+		serial:    make(map[WorkloadRef]bool),
+		notReady:  make(map[WorkloadRef]bool),
+	}
+	resolver := newWorkloadResolver(e.Client)
 
 	// Determine namespaces to search
-	namespaces := req.Spec.Namespaces
-	if len(namespaces) == 0 {
-		// List all namespaces
-		var nsList corev1.NamespaceList
-		if err := e.Client.List(ctx, &nsList); err != nil {
+	namespaces, err := e.getTargetNamespaces(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	pods, err := e.listOptedInPods(ctx, req, namespaces)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, pod := range pods {
+		workload, err := resolver.resolve(ctx, &pod)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve owner of pod %s/%s: %w", pod.Namespace, pod.Name, err)
+		}
+
+		if pod.Status.Phase == corev1.PodPending {
+			candidates.pending = append(candidates.pending, pendingPod{pod: pod, workload: workload})
+		}
+
+		reason, err := e.getSkipReason(ctx, &pod, workload, req)
+		if err != nil {
 			return nil, err
 		}
-		for _, ns := range nsList.Items {
-			namespaces = append(namespaces, ns.Name)
+		if reason != "" {
+			candidates.skipped = append(candidates.skipped, SkippedPod{
+				Namespace: pod.Namespace,
+				Name:      pod.Name,
+				Reason:    reason,
+			})
+			continue
+		}
+
+		candidates.pods = append(candidates.pods, pod)
+		candidates.workloads[client.ObjectKeyFromObject(&pod)] = workload
+		if getWorkloadAction(workload.Kind, req.Spec.WorkloadPolicies) == korev1alpha1.WorkloadActionSerial {
+			candidates.serial[workload] = true
+			// Wait until the previous replacement is Ready before moving another pod
+			ready, err := resolver.isReady(ctx, workload)
+			if err != nil {
+				return nil, err
+			}
+			if !ready {
+				candidates.notReady[workload] = true
+			}
 		}
 	}
 
-	// Build label selector
-	var selector labels.Selector
-	if req.Spec.Selector != nil {
-		var err error
-		selector, err = metav1.LabelSelectorAsSelector(req.Spec.Selector)
-		if err != nil {
-			return nil, fmt.Errorf("invalid selector: %w", err)
+	return candidates, nil
+}
+
+// listOptedInPods lists the pods in the namespaces that are opted in and match the request's selector.
+// Labelled pods are selected by the server or cache; pods opted in by their namespace default
+// carry no label, so they are listed from the uncached reader for those namespaces only.
+func (e *Engine) listOptedInPods(ctx context.Context, req *korev1alpha1.RebalanceRequest, namespaces []corev1.Namespace) ([]corev1.Pod, error) {
+	optInLabel := e.optInLabel(req)
+	labelled, err := podSelector(req.Spec.Selector, optInLabel, selection.Equals, "true")
+	if err != nil {
+		return nil, err
+	}
+	unlabelled, err := podSelector(req.Spec.Selector, optInLabel, selection.DoesNotExist)
+	if err != nil {
+		return nil, err
+	}
+
+	// The manager's cache only holds pods carrying the manager's opt-in label
+	var labelledReader client.Reader = e.Client
+	if optInLabel != e.OptInLabel {
+		labelledReader = e.reader()
+	}
+
+	nsByName := make(map[string]*corev1.Namespace, len(namespaces))
+	for i := range namespaces {
+		nsByName[namespaces[i].Name] = &namespaces[i]
+	}
+
+	var pods []corev1.Pod
+	if len(req.Spec.Namespaces) == 0 && req.Spec.NamespaceSelector == nil {
+		// Every namespace but the excluded ones, so a single cluster-wide list
+		var podList corev1.PodList
+		if err := labelledReader.List(ctx, &podList, client.MatchingLabelsSelector{Selector: labelled}); err != nil {
+			return nil, err
+		}
+		pods = podList.Items
+	} else {
+		for _, ns := range namespaces {
+			var podList corev1.PodList
+			if err := labelledReader.List(ctx, &podList, client.InNamespace(ns.Name),
+				client.MatchingLabelsSelector{Selector: labelled}); err != nil {
+				return nil, err
+			}
+			pods = append(pods, podList.Items...)
 		}
 	}
 
 	for _, ns := range namespaces {
-		// Skip system namespaces
-		if ns == "kube-system" || ns == "kube-public" || ns == "kube-node-lease" {
+		if ns.Annotations[NamespaceDefaultAnnotation] != "true" {
 			continue
 		}
-
 		var podList corev1.PodList
-		listOpts := []client.ListOption{client.InNamespace(ns)}
-		if err := e.Client.List(ctx, &podList, listOpts...); err != nil {
+		if err := e.reader().List(ctx, &podList, client.InNamespace(ns.Name),
+			client.MatchingLabelsSelector{Selector: unlabelled}); err != nil {
 			return nil, err
 		}
+		pods = append(pods, podList.Items...)
+	}
 
-		for _, pod := range podList.Items {
-			// Must have the rebalance enabled label
-			if pod.Labels[RebalanceEnabledLabel] != "true" {
-				continue
-			}
+	// Drop excluded namespaces and pods opted out by annotation
+	optedIn := pods[:0]
+	for _, pod := range pods {
+		ns, ok := nsByName[pod.Namespace]
+		if ok && isOptedIn(&pod, ns, optInLabel) {
+			optedIn = append(optedIn, pod)
+		}
+	}
+	return optedIn, nil
+}
 
-			// Must match additional selector if provided
-			if selector != nil && !selector.Matches(labels.Set(pod.Labels)) {
-				continue
-			}
+// podSelector combines the request's pod selector with a requirement on the opt-in label
+func podSelector(spec *metav1.LabelSelector, optInLabel string, op selection.Operator, values ...string) (labels.Selector, error) {
+	selector := labels.Everything()
+	if spec != nil {
+		var err error
+		if selector, err = metav1.LabelSelectorAsSelector(spec); err != nil {
+			return nil, fmt.Errorf("invalid selector: %w", err)
+		}
+	}
+	requirement, err := labels.NewRequirement(optInLabel, op, values)
+	if err != nil {
+		return nil, fmt.Errorf("invalid opt-in label %q: %w", optInLabel, err)
+	}
+	return selector.Add(*requirement), nil
+}
 
-			// Must be running and scheduled
-			if pod.Status.Phase != corev1.PodRunning {
-				continue
-			}
-			if pod.Spec.NodeName == "" {
-				continue
-			}
+// getSkipReason returns why a managed pod cannot be evicted, or an empty reason if it can
+func (e *Engine) getSkipReason(ctx context.Context, pod *corev1.Pod, workload WorkloadRef, req *korev1alpha1.RebalanceRequest) (SkipReason, error) {
+	// Must be running and scheduled
+	if pod.Status.Phase != corev1.PodRunning || pod.Spec.NodeName == "" {
+		return SkipReasonNotRunning, nil
+	}
 
-			// Skip pods owned by DaemonSets
-			if isOwnedByDaemonSet(&pod) {
-				continue
-			}
+	// Skip pods owned by DaemonSets
+	if isOwnedByDaemonSet(pod) {
+		return SkipReasonDaemonSet, nil
+	}
 
-			// Skip pods with local storage (unless they explicitly opt-in)
-			if hasLocalStorage(&pod) && pod.Labels["kore.boring.io/allow-local-storage-eviction"] != "true" {
-				continue
-			}
+	// Apply the policy for the top-level workload kind. Serial workloads are limited
+	// during victim selection, so their pods still count on their nodes.
+	if getWorkloadAction(workload.Kind, req.Spec.WorkloadPolicies) == korev1alpha1.WorkloadActionSkip {
+		if workload.Kind == OrphanWorkloadKind {
+			return SkipReasonOrphan, nil
+		}
+		return SkipReasonWorkloadPolicy, nil
+	}
+
+	// Skip pods with local storage the policy does not allow (unless they explicitly opt-in)
+	if pod.Labels[AllowLocalStorageEvictionLabel] != "true" {
+		return e.getLocalStorageSkipReason(ctx, pod, req.Spec.LocalStoragePolicy)
+	}
+
+	return "", nil
+}
 
-			allPods = append(allPods, pod)
+// getTargetNamespaces resolves the namespaces to search from the explicit list,
+// the namespace selector and the excluded namespaces
+func (e *Engine) getTargetNamespaces(ctx context.Context, req *korev1alpha1.RebalanceRequest) ([]corev1.Namespace, error) {
+	var listOpts []client.ListOption
+	if req.Spec.NamespaceSelector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(req.Spec.NamespaceSelector)
+		if err != nil {
+			return nil, fmt.Errorf("invalid namespace selector: %w", err)
 		}
+		listOpts = append(listOpts, client.MatchingLabelsSelector{Selector: selector})
 	}
 
-	return allPods, nil
+	var nsList corev1.NamespaceList
+	if err := e.Client.List(ctx, &nsList, listOpts...); err != nil {
+		return nil, err
+	}
+
+	// Explicit namespaces must also match the selector
+	var explicit map[string]bool
+	if len(req.Spec.Namespaces) > 0 {
+		explicit = make(map[string]bool, len(req.Spec.Namespaces))
+		for _, ns := range req.Spec.Namespaces {
+			explicit[ns] = true
+		}
+	}
+
+	// Unset means the system defaults; an explicit empty list excludes nothing
+	excluded := req.Spec.ExcludedNamespaces
+	if excluded == nil {
+		excluded = DefaultExcludedNamespaces
+	}
+	excludedSet := make(map[string]bool, len(excluded))
+	for _, ns := range excluded {
+		excludedSet[ns] = true
+	}
+
+	var namespaces []corev1.Namespace
+	for _, ns := range nsList.Items {
+		if explicit != nil && !explicit[ns.Name] {
+			continue
+		}
+		if excludedSet[ns.Name] {
+			continue
+		}
+		namespaces = append(namespaces, ns)
+	}
+	return namespaces, nil
 }
 
-// calculatePodsToEvict determines which pods should be evicted to balance across nodes proportionally
-func (e *Engine) calculatePodsToEvict(nodes []corev1.Node, pods []corev1.Pod, nodeTargets []korev1alpha1.NodeTarget) []corev1.Pod {
+// optInLabel returns the pod label that opts pods into rebalancing for the request
+func (e *Engine) optInLabel(req *korev1alpha1.RebalanceRequest) string {
+	if req.Spec.OptInLabel != "" {
+		return req.Spec.OptInLabel
+	}
+	if e.OptInLabel != "" {
+		return e.OptInLabel
+	}
+	return RebalanceEnabledLabel
+}
+
+// IsOptedIn checks if a pod is opted into rebalancing by the request
+func (e *Engine) IsOptedIn(pod *corev1.Pod, ns *corev1.Namespace, req *korev1alpha1.RebalanceRequest) bool {
+	return isOptedIn(pod, ns, e.optInLabel(req))
+}
+
+// calculatePodsToEvict determines which pods should be evicted to balance across nodes proportionally.
+// Returns the nodes that took part, sorted by name, along with the pods to evict.
+// unmanaged holds per-node counts of other pods, used when capacity accounting is enabled.
+// allow is consulted for each victim in selection order and may reject it, in which case the next pod on the node is tried.
+func (e *Engine) calculatePodsToEvict(nodes []corev1.Node, pods []corev1.Pod, unmanaged map[string]int, spec *korev1alpha1.RebalanceRequestSpec, allow func(pod *corev1.Pod) bool) ([]NodePodCount, []corev1.Pod, error) {
+	nodeTargets, err := compileNodeTargets(spec.NodeTargets)
+	if err != nil {
+		return nil, nil, err
+	}
+	var managedProfile corev1.ResourceList
+	if needsManagedProfile(nodeTargets) {
+		managedProfile = maxPodRequests(pods)
+	}
+
 	// Build node -> pods mapping
 	nodeMap := make(map[string]*corev1.Node)
 	nodePodMap := make(map[string][]corev1.Pod)
@@ -253,52 +857,107 @@ func (e *Engine) calculatePodsToEvict(nodes []corev1.Node, pods []corev1.Pod, no
 
 	// Convert to slice with max pod information
 	var nodeCounts []NodePodCount
+	var unmatched []NodePodCount
 	for nodeName, nodePods := range nodePodMap {
 		node := nodeMap[nodeName]
-		maxPods := e.getMaxPodsForNode(node, nodeTargets)
-		nodeCounts = append(nodeCounts, NodePodCount{
+		nc := NodePodCount{
 			NodeName: nodeName,
 			Node:     node,
 			PodCount: len(nodePods),
-			MaxPods:  maxPods,
 			Pods:     nodePods,
-		})
+		}
+		maxPods, ok := e.getMaxPodsForNode(node, nodeTargets, managedProfile)
+		if !ok {
+			unmatched = append(unmatched, nc)
+			continue
+		}
+		nc.MaxPods = maxPods
+		nodeCounts = append(nodeCounts, nc)
+	}
+	nodeCounts = append(nodeCounts, applyUnmatchedNodePolicy(nodeCounts, unmatched, spec.UnmatchedNodes)...)
+
+	if len(nodeCounts) == 0 {
+		return nil, nil, nil
+	}
+
+	// Pods on ineligible or excluded nodes are neither counted nor evicted
+	totalPods := 0
+	for _, nc := range nodeCounts {
+		totalPods += nc.PodCount
 	}
 
-	totalPods := len(pods)
+	// Adjust each node's capacity for load the rebalancer does not manage
+	accounting := spec.CapacityAccounting
+	for i := range nodeCounts {
+		nc := &nodeCounts[i]
+		nc.Capacity = nc.MaxPods
+		if accounting == nil {
+			continue
+		}
+		if nc.Capacity < 0 {
+			// Without node targets the node's pod limit is its capacity
+			nc.Capacity = allocatablePods(nc.Node)
+		}
+		if accounting.UnmanagedPods {
+			nc.Unmanaged = unmanaged[nc.NodeName]
+			nc.Capacity = max(0, nc.Capacity-nc.Unmanaged)
+		}
+		if accounting.NodePressure && isNodeUnderPressure(nc.Node) {
+			// Never a receiver: no room beyond the pods already there
+			nc.Pressure = true
+			nc.Capacity = min(nc.Capacity, nc.PodCount)
+		}
+	}
 
 	// Calculate target pods per node based on capacity-proportional distribution
-	if len(nodeTargets) > 0 {
+	if len(nodeTargets) > 0 || accounting != nil {
 		// Capacity-proportional mode: distribute pods based on each node's max capacity
 		// Target = (nodeMax / totalCapacity) * totalPods
 		totalCapacity := 0
 		for _, nc := range nodeCounts {
-			totalCapacity += nc.MaxPods
+			totalCapacity += nc.Capacity
 		}
 
 		// Calculate target for each node proportional to its capacity
 		for i := range nodeCounts {
+			nc := &nodeCounts[i]
+			proportional := nc.Capacity
 			if totalCapacity > 0 {
 				// Target = (nodeMax / totalCapacity) * totalPods
-				target := float64(nodeCounts[i].MaxPods) / float64(totalCapacity) * float64(totalPods)
+				target := float64(nc.Capacity) / float64(totalCapacity) * float64(totalPods)
 				// Use ceiling + 1 slack to avoid constant evictions
-				nodeCounts[i].MaxPods = int(target) + 1
+				proportional = int(target) + 1
+			}
+
+			switch spec.TargetMode {
+			case korev1alpha1.TargetModeHardCap:
+				nc.Target, nc.TargetSource = nc.Capacity, TargetSourceHardCap
+			case korev1alpha1.TargetModeBoth:
+				// The configured maximum is a ceiling on the proportional share
+				if nc.Capacity < proportional {
+					nc.Target, nc.TargetSource = nc.Capacity, TargetSourceHardCap
+				} else {
+					nc.Target, nc.TargetSource = proportional, TargetSourceProportional
+				}
+			default:
+				nc.Target, nc.TargetSource = proportional, TargetSourceProportional
 			}
 		}
 	} else {
 		// No node targets: use average-based limit (even spread)
-		avgPodsPerNode := float64(totalPods) / float64(len(nodes))
-		// Set max to average + 1 (allow some slack)
-		maxFromAvg := int(avgPodsPerNode) + 1
+		avgPodsPerNode := float64(totalPods) / float64(len(nodeCounts))
+		// Set target to average + 1 (allow some slack)
+		targetFromAvg := int(avgPodsPerNode) + 1
 		for i := range nodeCounts {
-			nodeCounts[i].MaxPods = maxFromAvg
+			nodeCounts[i].Target = targetFromAvg
+			nodeCounts[i].TargetSource = TargetSourceAverage
 		}
 	}
 
 	// Sort nodes by excess pods (descending) - nodes with most excess first
 	sort.Slice(nodeCounts, func(i, j int) bool {
-		excessI := nodeCounts[i].PodCount - nodeCounts[i].MaxPods
-		excessJ := nodeCounts[j].PodCount - nodeCounts[j].MaxPods
+		excessI := nodeCounts[i].PodCount - nodeCounts[i].Target
+		excessJ := nodeCounts[j].PodCount - nodeCounts[j].Target
 		return excessI > excessJ
 	})
 
@@ -306,7 +965,7 @@ func (e *Engine) calculatePodsToEvict(nodes []corev1.Node, pods []corev1.Pod, no
 	var podsToEvict []corev1.Pod
 	for _, nc := range nodeCounts {
 		// Only evict if exceeding target
-		excessPods := nc.PodCount - nc.MaxPods
+		excessPods := nc.PodCount - nc.Target
 		if excessPods <= 0 {
 			continue
 		}
@@ -318,45 +977,20 @@ func (e *Engine) calculatePodsToEvict(nodes []corev1.Node, pods []corev1.Pod, no
 			return podsOnNode[i].CreationTimestamp.After(podsOnNode[j].CreationTimestamp.Time)
 		})
 
-		for i := 0; i < excessPods && i < len(podsOnNode); i++ {
+		selected := 0
+		for i := 0; selected < excessPods && i < len(podsOnNode); i++ {
+			if allow != nil && !allow(&podsOnNode[i]) {
+				continue
+			}
 			podsToEvict = append(podsToEvict, podsOnNode[i])
+			selected++
 		}
 	}
 
-	return podsToEvict
-}
-
-// getMaxPodsForNode returns the maximum pod count for a node based on nodeTargets.
-// Returns -1 if no matching target is found (will use average later).
-func (e *Engine) getMaxPodsForNode(node *corev1.Node, nodeTargets []korev1alpha1.NodeTarget) int {
-	if len(nodeTargets) == 0 {
-		return -1
-	}
-
-	for _, target := range nodeTargets {
-		if matchesNodeSelector(node, target.NodeSelector) {
-			return int(target.MaxPodsPerNode)
-		}
-	}
-
-	// No matching target found - use a high default so we don't evict from unknown node types
-	// This allows pods to schedule on new node types without being immediately evicted
-	return 1000
-}
-
-// matchesNodeSelector checks if a node matches the given selector
-func matchesNodeSelector(node *corev1.Node, selector map[string]string) bool {
-	if len(selector) == 0 {
-		// Empty selector matches all nodes
-		return true
-	}
-
-	for key, value := range selector {
-		if nodeValue, ok := node.Labels[key]; !ok || nodeValue != value {
-			return false
-		}
-	}
-	return true
+	sort.Slice(nodeCounts, func(i, j int) bool {
+		return nodeCounts[i].NodeName < nodeCounts[j].NodeName
+	})
+	return nodeCounts, podsToEvict, nil
 }
 
 // evictPod evicts a pod using the eviction API
@@ -369,11 +1003,23 @@ func (e *Engine) evictPod(ctx context.Context, pod *corev1.Pod) error {
 		DeleteOptions: &metav1.DeleteOptions{},
 	}
 
-	return e.Client.SubResource("eviction").Create(ctx, pod, eviction)
+	ctx, span := tracer.Start(ctx, "EvictPod", trace.WithAttributes(tracing.Pod(pod)...))
+	err := e.Client.SubResource("eviction").Create(ctx, pod, eviction)
+	switch {
+	case err == nil:
+		span.SetAttributes(tracing.Result("Evicted"))
+	case apierrors.IsTooManyRequests(err):
+		// The API server refuses evictions that would violate a PodDisruptionBudget
+		span.SetAttributes(tracing.Result("DisruptionBudgetExceeded"))
+	default:
+		span.SetAttributes(tracing.Result("Failed"))
+	}
+	tracing.End(span, err)
+	return err
 }
 
-// isNodeReady checks if a node is in Ready condition
-func isNodeReady(node *corev1.Node) bool {
+// IsNodeReady checks if a node is in Ready condition
+func IsNodeReady(node *corev1.Node) bool {
 	for _, cond := range node.Status.Conditions {
 		if cond.Type == corev1.NodeReady {
 			return cond.Status == corev1.ConditionTrue
@@ -382,25 +1028,70 @@ func isNodeReady(node *corev1.Node) bool {
 	return false
 }
 
+// isNodeUnderPressure checks if a node reports memory, disk or PID pressure
+func isNodeUnderPressure(node *corev1.Node) bool {
+	for _, cond := range node.Status.Conditions {
+		switch cond.Type {
+		case corev1.NodeMemoryPressure, corev1.NodeDiskPressure, corev1.NodePIDPressure:
+			if cond.Status == corev1.ConditionTrue {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// allocatablePods returns the number of pods a node can run
+func allocatablePods(node *corev1.Node) int {
+	if podLimit, ok := node.Status.Allocatable[corev1.ResourcePods]; ok {
+		return int(podLimit.Value())
+	}
+	return 0
+}
+
 // isNodeUnschedulable checks if a node is cordoned
 func isNodeUnschedulable(node *corev1.Node) bool {
 	return node.Spec.Unschedulable
 }
 
-// isOwnedByDaemonSet checks if a pod is owned by a DaemonSet
-func isOwnedByDaemonSet(pod *corev1.Pod) bool {
-	for _, ref := range pod.OwnerReferences {
-		if ref.Kind == "DaemonSet" {
-			return true
+// isOptedIn checks if a pod is opted into rebalancing.
+// The pod's own label wins over the namespace default, and the exclude annotation wins over both.
+func isOptedIn(pod *corev1.Pod, ns *corev1.Namespace, label string) bool {
+	if pod.Annotations[RebalanceExcludeAnnotation] == "true" {
+		return false
+	}
+	if value, ok := pod.Labels[label]; ok {
+		return value == "true"
+	}
+	return ns.Annotations[NamespaceDefaultAnnotation] == "true"
+}
+
+// ToleratesNodeTaints checks if the tolerations allow scheduling onto the node.
+// PreferNoSchedule taints are ignored since they never block scheduling.
+func ToleratesNodeTaints(node *corev1.Node, tolerations []corev1.Toleration) bool {
+	for i := range node.Spec.Taints {
+		taint := &node.Spec.Taints[i]
+		if taint.Effect == corev1.TaintEffectPreferNoSchedule {
+			continue
+		}
+		tolerated := false
+		for j := range tolerations {
+			if tolerations[j].ToleratesTaint(taint) {
+				tolerated = true
+				break
+			}
+		}
+		if !tolerated {
+			return false
 		}
 	}
-	return false
+	return true
 }
 
-// hasLocalStorage checks if a pod uses local storage
-func hasLocalStorage(pod *corev1.Pod) bool {
-	for _, vol := range pod.Spec.Volumes {
-		if vol.EmptyDir != nil || vol.HostPath != nil {
+// isOwnedByDaemonSet checks if a pod is owned by a DaemonSet
+func isOwnedByDaemonSet(pod *corev1.Pod) bool {
+	for _, ref := range pod.OwnerReferences {
+		if ref.Kind == "DaemonSet" {
 			return true
 		}
 	}
@@ -0,0 +1,91 @@
+package rebalancer
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	korev1alpha1 "github.com/cxfcxf/pod-rebalancer/api/v1alpha1"
+)
+
+func TestCountUnmanagedPodsSkipsTerminalPodsAndCandidates(t *testing.T) {
+	managed := testPod("managed", "a", time.Hour)
+	unmanaged := testPod("unmanaged", "a", time.Hour)
+	finished := testPod("finished", "a", time.Hour)
+	finished.Status.Phase = corev1.PodSucceeded
+	failed := testPod("failed", "b", time.Hour)
+	failed.Status.Phase = corev1.PodFailed
+	elsewhere := testPod("elsewhere", "c", time.Hour)
+
+	e := testEngine(t, nil, testNode("a", "1"), testNode("b", "1"), managed, unmanaged, finished, failed, elsewhere)
+	candidates := &candidateSet{workloads: map[types.NamespacedName]WorkloadRef{
+		{Namespace: "shop", Name: "managed"}: {},
+	}}
+
+	counts, err := e.countUnmanagedPods(context.Background(), []corev1.Node{*testNode("a", "1"), *testNode("b", "1")}, candidates)
+	if err != nil {
+		t.Fatalf("countUnmanagedPods() error = %v", err)
+	}
+	// Only eligible nodes are listed, so the pod on c is never counted
+	if len(counts) != 1 || counts["a"] != 1 {
+		t.Errorf("counts = %v, want a=1", counts)
+	}
+}
+
+func TestSerialWorkloadNotReadyCountsButIsNotEvicted(t *testing.T) {
+	tests := []struct {
+		name          string
+		readyReplicas int32
+		wantVictims   string
+	}{
+		{name: "ready", readyReplicas: 4, wantVictims: "newest"},
+		{name: "not ready", readyReplicas: 3, wantVictims: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			replicas := int32(4)
+			rs := &appsv1.ReplicaSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "shop"},
+				Spec:       appsv1.ReplicaSetSpec{Replicas: &replicas},
+				Status:     appsv1.ReplicaSetStatus{Replicas: replicas, ReadyReplicas: tt.readyReplicas},
+			}
+			e := testEngine(t, nil, rs,
+				testNode("a", "1"), testNode("b", "1"),
+				testPod("oldest", "a", 4*time.Hour), testPod("old", "a", 3*time.Hour),
+				testPod("new", "a", 2*time.Hour), testPod("newest", "a", time.Hour),
+			)
+			req := &korev1alpha1.RebalanceRequest{
+				ObjectMeta: metav1.ObjectMeta{Name: "shop", Namespace: "default"},
+				Spec: korev1alpha1.RebalanceRequestSpec{WorkloadPolicies: []korev1alpha1.WorkloadPolicy{
+					{Kind: "ReplicaSet", Action: korev1alpha1.WorkloadActionSerial},
+				}},
+			}
+
+			plan, err := e.Plan(context.Background(), req)
+			if err != nil {
+				t.Fatalf("Plan() error = %v", err)
+			}
+			if got := strings.Join(victimNames(plan), ","); got != tt.wantVictims {
+				t.Errorf("victims = %q, want %q", got, tt.wantVictims)
+			}
+			// The workload's pods count on their node either way
+			if len(plan.Skipped) != 0 {
+				t.Errorf("skipped = %v, want none", plan.Skipped)
+			}
+			for _, nc := range plan.Nodes {
+				if nc.NodeName == "a" && nc.PodCount != 4 {
+					t.Errorf("node a counts %d pods, want 4", nc.PodCount)
+				}
+			}
+			if plan.Balanced() {
+				t.Error("Balanced() = true with node a above its target")
+			}
+		})
+	}
+}
@@ -0,0 +1,246 @@
+package rebalancer
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	korev1alpha1 "github.com/cxfcxf/pod-rebalancer/api/v1alpha1"
+)
+
+// DecisionReason explains why a pod was or was not selected for eviction.
+// Managed pods skipped before selection use their SkipReason.
+type DecisionReason string
+
+const (
+	// ReasonSelected pods are evicted by the run
+	ReasonSelected DecisionReason = "Selected"
+	// ReasonNodeWithinTarget pods are on a node at or below its target
+	ReasonNodeWithinTarget DecisionReason = "NodeWithinTarget"
+	// ReasonRankedBelowCut pods are on a node above its target, but other pods were evicted first
+	ReasonRankedBelowCut DecisionReason = "RankedBelowCut"
+	// ReasonEvictionLimit pods were ranked for eviction but held back by the eviction history
+	// limits or the Serial workload action
+	ReasonEvictionLimit DecisionReason = "EvictionLimit"
+	// ReasonNodeNotEligible pods are on a node that takes no part in balancing
+	ReasonNodeNotEligible DecisionReason = "NodeNotEligible"
+	// ReasonNotPlanned pods are managed, but the run stopped before selecting victims
+	ReasonNotPlanned DecisionReason = "NotPlanned"
+	// ReasonNotOptedIn pods lack the opt-in label and a namespace default
+	ReasonNotOptedIn DecisionReason = "NotOptedIn"
+	// ReasonOptedOut pods carry the exclude annotation
+	ReasonOptedOut DecisionReason = "OptedOut"
+	// ReasonNamespaceNotTargeted pods are in a namespace the request does not cover
+	ReasonNamespaceNotTargeted DecisionReason = "NamespaceNotTargeted"
+	// ReasonSelectorMismatch pods do not match the request's pod selector
+	ReasonSelectorMismatch DecisionReason = "SelectorMismatch"
+	// ReasonNotFound pods do not exist
+	ReasonNotFound DecisionReason = "NotFound"
+)
+
+// skipMessages describe each skip reason
+var skipMessages = map[SkipReason]string{
+	SkipReasonNotRunning:            "The pod is not Running on a node",
+	SkipReasonDaemonSet:             "DaemonSet pods are never moved",
+	SkipReasonOrphan:                "The pod has no owning controller to recreate it",
+	SkipReasonWorkloadPolicy:        "The workload policy for its owner's kind is Skip",
+	SkipReasonMemoryEmptyDir:        "The pod uses a memory-backed emptyDir volume",
+	SkipReasonEmptyDir:              "The pod uses an emptyDir volume the local storage policy does not allow",
+	SkipReasonHostPath:              "The pod uses a hostPath volume the local storage policy does not allow",
+	SkipReasonLocalPersistentVolume: "The pod uses a local PersistentVolume the local storage policy does not allow",
+}
+
+// Decision explains what a run decided for one pod
+type Decision struct {
+	Pod      string         `json:"pod"`
+	Node     string         `json:"node,omitempty"`
+	Workload string         `json:"workload,omitempty"`
+	Reason   DecisionReason `json:"reason"`
+	Message  string         `json:"message"`
+}
+
+// Selected returns true if the run evicts the pod
+func (d *Decision) Selected() bool {
+	return d.Reason == ReasonSelected
+}
+
+// trackSelection wraps an eviction guard to record which pods victim selection offered
+// and which the guard held back. Returns the wrapped guard.
+func (p *Plan) trackSelection(guard func(pod *corev1.Pod) bool) func(pod *corev1.Pod) bool {
+	p.offered = make(map[types.NamespacedName]bool)
+	p.held = make(map[types.NamespacedName]bool)
+	return func(pod *corev1.Pod) bool {
+		key := client.ObjectKeyFromObject(pod)
+		p.offered[key] = true
+		if guard != nil && !guard(pod) {
+			p.held[key] = true
+			return false
+		}
+		return true
+	}
+}
+
+// Balanced returns true if the plan found nothing to evict and no guard held a pod back,
+// so running again would not move anything
+func (p *Plan) Balanced() bool {
+	return len(p.Victims) == 0 && len(p.held) == 0
+}
+
+// Decisions explains the plan's decision for every managed pod, sorted by pod
+func (p *Plan) Decisions() []Decision {
+	victims := make(map[types.NamespacedName]bool, len(p.Victims))
+	for i := range p.Victims {
+		victims[client.ObjectKeyFromObject(&p.Victims[i])] = true
+	}
+	nodes := make(map[string]*NodePodCount, len(p.Nodes))
+	for i := range p.Nodes {
+		nodes[p.Nodes[i].NodeName] = &p.Nodes[i]
+	}
+	// Selection only offers pods on nodes above their target
+	overTarget := make(map[string]bool)
+	for i := range p.candidates {
+		if p.offered[client.ObjectKeyFromObject(&p.candidates[i])] {
+			overTarget[p.candidates[i].Spec.NodeName] = true
+		}
+	}
+
+	decisions := make([]Decision, 0, len(p.candidates)+len(p.Skipped))
+	for i := range p.candidates {
+		pod := &p.candidates[i]
+		key := client.ObjectKeyFromObject(pod)
+		decision := Decision{
+			Pod:      key.String(),
+			Node:     pod.Spec.NodeName,
+			Workload: p.Workloads[key].String(),
+		}
+		nc := nodes[pod.Spec.NodeName]
+		switch {
+		case victims[key]:
+			decision.Reason = ReasonSelected
+			decision.Message = p.nodeMessage(nc, "above")
+		case p.Tripped != "" || len(p.Nodes) == 0:
+			decision.Reason = ReasonNotPlanned
+			decision.Message = p.Message
+		case nc == nil:
+			decision.Reason = ReasonNodeNotEligible
+			decision.Message = fmt.Sprintf("Node %s is not ready, cordoned, excluded by the request's node selectors or targets, "+
+				"or has taints its workloads do not tolerate", pod.Spec.NodeName)
+		case p.held[key]:
+			decision.Reason = ReasonEvictionLimit
+			decision.Message = "Ranked for eviction, but held back by the eviction history limits or the Serial workload action, " +
+				"which moves one pod per run and waits until all replicas are Ready"
+		case overTarget[pod.Spec.NodeName]:
+			decision.Reason = ReasonRankedBelowCut
+			if p.ByLoad {
+				decision.Message = p.nodeMessage(nc, "above") + ", but heavier pods were evicted first"
+			} else {
+				decision.Message = p.nodeMessage(nc, "above") + ", but newer pods were evicted first"
+			}
+		default:
+			decision.Reason = ReasonNodeWithinTarget
+			decision.Message = p.nodeMessage(nc, "within")
+		}
+		decisions = append(decisions, decision)
+	}
+
+	for _, skipped := range p.Skipped {
+		decisions = append(decisions, Decision{
+			Pod:     skipped.Namespace + "/" + skipped.Name,
+			Reason:  DecisionReason(skipped.Reason),
+			Message: skipMessages[skipped.Reason],
+		})
+	}
+
+	sort.Slice(decisions, func(i, j int) bool {
+		return decisions[i].Pod < decisions[j].Pod
+	})
+	return decisions
+}
+
+// nodeMessage describes a node's position against its target
+func (p *Plan) nodeMessage(nc *NodePodCount, position string) string {
+	if p.ByLoad {
+		return fmt.Sprintf("Node %s is %s the load threshold at %d%% utilization", nc.NodeName, position, int(nc.Utilization*100))
+	}
+	return fmt.Sprintf("Node %s has %d pods, %s its %s target of %d", nc.NodeName, nc.PodCount, position, nc.TargetSource, nc.Target)
+}
+
+// Explain plans a run for the request and explains the decision for one pod,
+// including pods the run never considered
+func (e *Engine) Explain(ctx context.Context, req *korev1alpha1.RebalanceRequest, key types.NamespacedName) (*Decision, error) {
+	plan, err := e.Plan(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	for _, decision := range plan.Decisions() {
+		if decision.Pod == key.String() {
+			return &decision, nil
+		}
+	}
+	return e.explainUnlisted(ctx, req, key)
+}
+
+// explainUnlisted explains why a pod was not listed as managed by the request
+func (e *Engine) explainUnlisted(ctx context.Context, req *korev1alpha1.RebalanceRequest, key types.NamespacedName) (*Decision, error) {
+	decision := &Decision{Pod: key.String()}
+	var pod corev1.Pod
+	if err := e.reader().Get(ctx, key, &pod); err != nil {
+		if apierrors.IsNotFound(err) {
+			decision.Reason = ReasonNotFound
+			decision.Message = "The pod does not exist"
+			return decision, nil
+		}
+		return nil, err
+	}
+	decision.Node = pod.Spec.NodeName
+
+	namespaces, err := e.getTargetNamespaces(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	var ns *corev1.Namespace
+	for i := range namespaces {
+		if namespaces[i].Name == pod.Namespace {
+			ns = &namespaces[i]
+			break
+		}
+	}
+	selector := labels.Everything()
+	if req.Spec.Selector != nil {
+		if selector, err = metav1.LabelSelectorAsSelector(req.Spec.Selector); err != nil {
+			return nil, fmt.Errorf("invalid selector: %w", err)
+		}
+	}
+
+	optInLabel := e.optInLabel(req)
+	switch {
+	case ns == nil:
+		decision.Reason = ReasonNamespaceNotTargeted
+		decision.Message = fmt.Sprintf("Namespace %s is excluded, or not selected by the request's namespaces or namespaceSelector", pod.Namespace)
+	case pod.Annotations[RebalanceExcludeAnnotation] == "true":
+		decision.Reason = ReasonOptedOut
+		decision.Message = fmt.Sprintf("The pod has the %s annotation", RebalanceExcludeAnnotation)
+	case !isOptedIn(&pod, ns, optInLabel):
+		decision.Reason = ReasonNotOptedIn
+		if value, ok := pod.Labels[optInLabel]; ok {
+			decision.Message = fmt.Sprintf("The pod's %s label is %q, not \"true\"", optInLabel, value)
+		} else {
+			decision.Message = fmt.Sprintf("The pod has no %s label and namespace %s has no %s annotation",
+				optInLabel, pod.Namespace, NamespaceDefaultAnnotation)
+		}
+	case !selector.Matches(labels.Set(pod.Labels)):
+		decision.Reason = ReasonSelectorMismatch
+		decision.Message = "The pod does not match the request's selector"
+	default:
+		decision.Reason = ReasonNotPlanned
+		decision.Message = "The pod is managed but was not seen by the run; it may have just been created"
+	}
+	return decision, nil
+}
@@ -0,0 +1,92 @@
+package rebalancer
+
+import (
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	korev1alpha1 "github.com/cxfcxf/pod-rebalancer/api/v1alpha1"
+)
+
+// DefaultEvictionWindow is how long evictions are remembered when a request does not set a window
+const DefaultEvictionWindow = 10 * time.Minute
+
+// Eviction records a pod evicted during a run
+type Eviction struct {
+	Namespace string
+	Name      string
+	Workload  WorkloadRef
+	Node      string
+	Time      time.Time
+}
+
+// EvictionWindow returns how long a request remembers its evictions
+func EvictionWindow(spec *korev1alpha1.RebalanceRequestSpec) time.Duration {
+	if spec.EvictionHistory != nil && spec.EvictionHistory.WindowSeconds > 0 {
+		return time.Duration(spec.EvictionHistory.WindowSeconds) * time.Second
+	}
+	return DefaultEvictionWindow
+}
+
+// evictionHistory indexes a request's evictions within the window
+type evictionHistory struct {
+	maxPerWorkload int
+	perWorkload    map[string]int
+	// lastFromNode is the latest eviction of each workload from each node
+	lastFromNode map[string]time.Time
+}
+
+// newEvictionHistory indexes the recent evictions in the request's status.
+// The node bounce check always applies; the per-workload limit only with an eviction history policy.
+func newEvictionHistory(req *korev1alpha1.RebalanceRequest, now time.Time) *evictionHistory {
+	history := &evictionHistory{
+		perWorkload:  make(map[string]int),
+		lastFromNode: make(map[string]time.Time),
+	}
+	if policy := req.Spec.EvictionHistory; policy != nil {
+		history.maxPerWorkload = int(policy.MaxPerWorkload)
+	}
+	cutoff := now.Add(-EvictionWindow(&req.Spec))
+	for _, record := range req.Status.RecentEvictions {
+		if record.Time.Time.Before(cutoff) {
+			continue
+		}
+		history.perWorkload[record.Workload]++
+		key := record.Workload + "@" + record.Node
+		if record.Time.Time.After(history.lastFromNode[key]) {
+			history.lastFromNode[key] = record.Time.Time
+		}
+	}
+	return history
+}
+
+// allows checks if the workload is below its eviction limit and the pod is not a replacement
+// that landed back on a node the workload was just evicted from
+func (h *evictionHistory) allows(pod *corev1.Pod, workload WorkloadRef) bool {
+	if h.maxPerWorkload > 0 && h.perWorkload[workload.String()] >= h.maxPerWorkload {
+		return false
+	}
+	last, ok := h.lastFromNode[workload.String()+"@"+pod.Spec.NodeName]
+	return !ok || !pod.CreationTimestamp.Time.After(last)
+}
+
+// record counts a pod selected for eviction in this run against its workload's limit
+func (h *evictionHistory) record(workload WorkloadRef) {
+	h.perWorkload[workload.String()]++
+}
+
+// newEvictionGuard returns a filter for victim selection that applies the eviction history
+// limits and allows only one eviction per run for workloads with the Serial action
+func newEvictionGuard(req *korev1alpha1.RebalanceRequest, candidates *candidateSet) func(pod *corev1.Pod) bool {
+	serial := newWorkloadGuard(candidates)
+	history := newEvictionHistory(req, time.Now())
+	return func(pod *corev1.Pod) bool {
+		workload := candidates.workloads[client.ObjectKeyFromObject(pod)]
+		if !history.allows(pod, workload) || !serial(pod) {
+			return false
+		}
+		history.record(workload)
+		return true
+	}
+}
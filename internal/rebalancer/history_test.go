@@ -0,0 +1,99 @@
+package rebalancer
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	korev1alpha1 "github.com/cxfcxf/pod-rebalancer/api/v1alpha1"
+)
+
+func TestEvictionGuardNodeBounce(t *testing.T) {
+	web := WorkloadRef{Kind: "Deployment", Namespace: "shop", Name: "web"}
+	// web lost a pod on node a 5 minutes ago; the replacement landed back on a 3 minutes ago
+	replacement := testPod("replacement", "a", 3*time.Minute)
+	older := testPod("older", "a", time.Hour)
+	elsewhere := testPod("elsewhere", "b", 3*time.Minute)
+	candidates := &candidateSet{workloads: map[types.NamespacedName]WorkloadRef{
+		{Namespace: "shop", Name: "replacement"}: web,
+		{Namespace: "shop", Name: "older"}:       web,
+		{Namespace: "shop", Name: "elsewhere"}:   web,
+	}}
+	recent := []korev1alpha1.EvictionRecord{{
+		Pod: "shop/web-1", Workload: web.String(), Node: "a", Time: metav1.NewTime(time.Now().Add(-5 * time.Minute)),
+	}}
+
+	tests := []struct {
+		name             string
+		policy           *korev1alpha1.EvictionHistoryPolicy
+		wantReplacement  bool
+		wantOlder        bool
+		wantOtherNodePod bool
+	}{
+		// The bounce check applies without a policy, within the default window
+		{name: "default window", policy: nil, wantOlder: true, wantOtherNodePod: true},
+		{name: "window expired", policy: &korev1alpha1.EvictionHistoryPolicy{WindowSeconds: 60}, wantReplacement: true, wantOlder: true, wantOtherNodePod: true},
+		{name: "long window", policy: &korev1alpha1.EvictionHistoryPolicy{WindowSeconds: 3600}, wantOlder: true, wantOtherNodePod: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := &korev1alpha1.RebalanceRequest{
+				Spec:   korev1alpha1.RebalanceRequestSpec{EvictionHistory: tt.policy},
+				Status: korev1alpha1.RebalanceRequestStatus{RecentEvictions: recent},
+			}
+			// A fresh guard per pod, so the per-workload count does not interfere
+			if got := newEvictionGuard(req, candidates)(replacement); got != tt.wantReplacement {
+				t.Errorf("replacement allowed = %v, want %v", got, tt.wantReplacement)
+			}
+			if got := newEvictionGuard(req, candidates)(older); got != tt.wantOlder {
+				t.Errorf("pod older than the eviction allowed = %v, want %v", got, tt.wantOlder)
+			}
+			if got := newEvictionGuard(req, candidates)(elsewhere); got != tt.wantOtherNodePod {
+				t.Errorf("pod on another node allowed = %v, want %v", got, tt.wantOtherNodePod)
+			}
+		})
+	}
+}
+
+func TestEvictionGuardMaxPerWorkload(t *testing.T) {
+	web := WorkloadRef{Kind: "Deployment", Namespace: "shop", Name: "web"}
+	pods := []string{"web-2", "web-3", "web-4"}
+	candidates := &candidateSet{workloads: map[types.NamespacedName]WorkloadRef{}}
+	for _, name := range pods {
+		candidates.workloads[types.NamespacedName{Namespace: "shop", Name: name}] = web
+	}
+	recent := []korev1alpha1.EvictionRecord{{
+		Pod: "shop/web-1", Workload: web.String(), Node: "c", Time: metav1.NewTime(time.Now().Add(-time.Minute)),
+	}}
+
+	tests := []struct {
+		name        string
+		policy      *korev1alpha1.EvictionHistoryPolicy
+		wantAllowed int
+	}{
+		{name: "opt-in only", policy: nil, wantAllowed: 3},
+		{name: "no limit", policy: &korev1alpha1.EvictionHistoryPolicy{}, wantAllowed: 3},
+		// One eviction is already recorded in the window, so one more is allowed
+		{name: "limit", policy: &korev1alpha1.EvictionHistoryPolicy{MaxPerWorkload: 2}, wantAllowed: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := &korev1alpha1.RebalanceRequest{
+				Spec:   korev1alpha1.RebalanceRequestSpec{EvictionHistory: tt.policy},
+				Status: korev1alpha1.RebalanceRequestStatus{RecentEvictions: recent},
+			}
+			guard := newEvictionGuard(req, candidates)
+			allowed := 0
+			for _, name := range pods {
+				if guard(testPod(name, "a", time.Hour)) {
+					allowed++
+				}
+			}
+			if allowed != tt.wantAllowed {
+				t.Errorf("allowed %d evictions, want %d", allowed, tt.wantAllowed)
+			}
+		})
+	}
+}
@@ -0,0 +1,272 @@
+package rebalancer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	korev1alpha1 "github.com/cxfcxf/pod-rebalancer/api/v1alpha1"
+)
+
+// ErrLoadUnavailable is returned when no load can be read, e.g. metrics-server is not installed
+var ErrLoadUnavailable = errors.New("load measurements unavailable")
+
+// LoadProvider measures the load of nodes and the pods running on them
+type LoadProvider interface {
+	// Load measures the given nodes and their pods using the request's load settings.
+	// Returns an error wrapping ErrLoadUnavailable if the source cannot be reached.
+	Load(ctx context.Context, nodes []corev1.Node, settings *korev1alpha1.LoadBalancing) (*LoadReport, error)
+}
+
+// LoadReport is one measurement of nodes and pods, in a single unit
+type LoadReport struct {
+	// Nodes is the load of each node by node name
+	Nodes map[string]float64
+
+	// Pods is the load of each pod
+	Pods map[types.NamespacedName]float64
+
+	// Capacity is each node's capacity in the same unit as its load.
+	// Nil if the unit has no capacity, in which case load is compared relative to the average.
+	Capacity map[string]float64
+
+	// NodeLoadFromPods sums the pod loads on each node instead of reading Nodes
+	NodeLoadFromPods bool
+}
+
+const (
+	// TargetSourceLoad marks targets produced by load-aware balancing
+	TargetSourceLoad = "Load"
+
+	// maxLoadSamples bounds the smoothing window kept per node and pod
+	maxLoadSamples = 10
+
+	// loadSampleTTL drops samples for nodes and pods that have not been measured recently
+	loadSampleTTL = time.Hour
+)
+
+// loadSeries is the recent measurements of one node or pod
+type loadSeries struct {
+	values  []float64
+	updated time.Time
+}
+
+// loadSamples keeps recent measurements for smoothing across runs
+type loadSamples struct {
+	mu     sync.Mutex
+	series map[string]*loadSeries
+}
+
+// add records a measurement and returns the average of the most recent window measurements
+func (s *loadSamples) add(key string, value float64, window int) float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.series == nil {
+		s.series = make(map[string]*loadSeries)
+	}
+	series, ok := s.series[key]
+	if !ok {
+		series = &loadSeries{}
+		s.series[key] = series
+	}
+	series.values = append(series.values, value)
+	if len(series.values) > maxLoadSamples {
+		series.values = series.values[len(series.values)-maxLoadSamples:]
+	}
+	series.updated = time.Now()
+
+	recent := series.values
+	if window > 0 && len(recent) > window {
+		recent = recent[len(recent)-window:]
+	}
+	sum := 0.0
+	for _, v := range recent {
+		sum += v
+	}
+	return sum / float64(len(recent))
+}
+
+// prune drops series that have not been updated within the TTL
+func (s *loadSamples) prune() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cutoff := time.Now().Add(-loadSampleTTL)
+	for key, series := range s.series {
+		if series.updated.Before(cutoff) {
+			delete(s.series, key)
+		}
+	}
+}
+
+// loadSettings returns the load balancing settings with defaults applied
+func loadSettings(spec *korev1alpha1.LoadBalancing) korev1alpha1.LoadBalancing {
+	settings := korev1alpha1.LoadBalancing{
+		Source:           korev1alpha1.LoadSourceMetrics,
+		Resource:         corev1.ResourceCPU,
+		ThresholdPercent: 10,
+		Samples:          3,
+	}
+	if spec == nil {
+		return settings
+	}
+	settings.Prometheus = spec.Prometheus
+	if spec.Source != "" {
+		settings.Source = spec.Source
+	}
+	if spec.Resource != "" {
+		settings.Resource = spec.Resource
+	}
+	if spec.ThresholdPercent > 0 {
+		settings.ThresholdPercent = spec.ThresholdPercent
+	}
+	if spec.Samples > 0 {
+		settings.Samples = spec.Samples
+	}
+	return settings
+}
+
+// loadSignal names the measured signal so samples of different signals are smoothed separately
+func loadSignal(settings *korev1alpha1.LoadBalancing) string {
+	if settings.Source == korev1alpha1.LoadSourcePrometheus && settings.Prometheus != nil {
+		return string(settings.Source) + "/" + settings.Prometheus.PodQuery + "/" + settings.Prometheus.NodeQuery
+	}
+	return string(settings.Source) + "/" + string(settings.Resource)
+}
+
+// calculateLoadPodsToEvict determines which pods to evict from nodes whose smoothed utilisation
+// exceeds the average by more than the threshold. The heaviest pods on each hot node are picked
+// until its projected utilisation is back within the threshold.
+// Samples are kept per request, so each request's window fills only from its own runs.
+// Returns ErrLoadUnavailable if no usable load could be read.
+func (e *Engine) calculateLoadPodsToEvict(ctx context.Context, req types.NamespacedName, nodes []corev1.Node, pods []corev1.Pod, spec *korev1alpha1.RebalanceRequestSpec, allow func(pod *corev1.Pod) bool) ([]NodePodCount, []corev1.Pod, error) {
+	settings := loadSettings(spec.Load)
+	provider, ok := e.LoadProviders[settings.Source]
+	if !ok || provider == nil {
+		return nil, nil, fmt.Errorf("%w: no provider for source %s", ErrLoadUnavailable, settings.Source)
+	}
+
+	report, err := provider.Load(ctx, nodes, &settings)
+	if err != nil {
+		return nil, nil, err
+	}
+	e.samples.prune()
+	signal := req.String() + "/" + loadSignal(&settings)
+	window := int(settings.Samples)
+
+	nodePods := make(map[string][]corev1.Pod)
+	for _, pod := range pods {
+		nodePods[pod.Spec.NodeName] = append(nodePods[pod.Spec.NodeName], pod)
+	}
+
+	// Smoothed load of every node that was measured; nodes without a measurement cannot be judged.
+	// With capacity, node and pod load become shares of the node's capacity.
+	var nodeCounts []NodePodCount
+	podLoad := make(map[types.NamespacedName]float64, len(pods))
+	totalUtilization := 0.0
+	for i := range nodes {
+		node := &nodes[i]
+		scale := 1.0
+		if report.Capacity != nil {
+			capacity := report.Capacity[node.Name]
+			if capacity <= 0 {
+				continue
+			}
+			scale = 1 / capacity
+		}
+
+		nodeLoad, measured := report.Nodes[node.Name]
+		for j := range nodePods[node.Name] {
+			key := client.ObjectKeyFromObject(&nodePods[node.Name][j])
+			load, ok := report.Pods[key]
+			if !ok {
+				continue
+			}
+			podLoad[key] = e.samples.add("pod/"+signal+"/"+key.String(), load*scale, window)
+			if report.NodeLoadFromPods {
+				nodeLoad += load
+				measured = true
+			}
+		}
+		if !measured {
+			continue
+		}
+
+		utilization := e.samples.add("node/"+signal+"/"+node.Name, nodeLoad*scale, window)
+		totalUtilization += utilization
+		nodeCounts = append(nodeCounts, NodePodCount{
+			NodeName:     node.Name,
+			Node:         node,
+			PodCount:     len(nodePods[node.Name]),
+			MaxPods:      -1,
+			Capacity:     -1,
+			Utilization:  utilization,
+			Target:       len(nodePods[node.Name]),
+			TargetSource: TargetSourceLoad,
+			Pods:         nodePods[node.Name],
+		})
+	}
+	if len(nodeCounts) == 0 || totalUtilization <= 0 {
+		return nil, nil, ErrLoadUnavailable
+	}
+	average := totalUtilization / float64(len(nodeCounts))
+
+	// Without capacity the units are arbitrary, so load is judged relative to the average
+	if report.Capacity == nil {
+		for i := range nodeCounts {
+			nodeCounts[i].Utilization /= average
+		}
+		for key := range podLoad {
+			podLoad[key] /= average
+		}
+		average = 1
+	}
+	bound := average + float64(settings.ThresholdPercent)/100
+
+	// Hottest nodes first
+	sort.Slice(nodeCounts, func(i, j int) bool {
+		return nodeCounts[i].Utilization > nodeCounts[j].Utilization
+	})
+
+	var podsToEvict []corev1.Pod
+	for i := range nodeCounts {
+		nc := &nodeCounts[i]
+		if nc.Utilization <= bound {
+			continue
+		}
+
+		// Heaviest pods first - each eviction moves the most load
+		podsOnNode := make([]corev1.Pod, len(nc.Pods))
+		copy(podsOnNode, nc.Pods)
+		sort.SliceStable(podsOnNode, func(i, j int) bool {
+			return podLoad[client.ObjectKeyFromObject(&podsOnNode[i])] > podLoad[client.ObjectKeyFromObject(&podsOnNode[j])]
+		})
+
+		projected := nc.Utilization
+		for j := 0; projected > bound && j < len(podsOnNode); j++ {
+			load := podLoad[client.ObjectKeyFromObject(&podsOnNode[j])]
+			if load <= 0 {
+				break
+			}
+			if allow != nil && !allow(&podsOnNode[j]) {
+				continue
+			}
+			podsToEvict = append(podsToEvict, podsOnNode[j])
+			projected -= load
+			nc.Target--
+		}
+	}
+
+	sort.Slice(nodeCounts, func(i, j int) bool {
+		return nodeCounts[i].NodeName < nodeCounts[j].NodeName
+	})
+	return nodeCounts, podsToEvict, nil
+}
@@ -0,0 +1,144 @@
+package rebalancer
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	korev1alpha1 "github.com/cxfcxf/pod-rebalancer/api/v1alpha1"
+)
+
+// metricsGroupVersion is the resource metrics API served by metrics-server
+var metricsGroupVersion = schema.GroupVersion{Group: "metrics.k8s.io", Version: "v1beta1"}
+
+// metricsAPISource reads NodeMetrics and PodMetrics from the metrics.k8s.io API
+type metricsAPISource struct {
+	reader client.Reader
+}
+
+// NewMetricsAPISource creates a LoadProvider backed by the metrics.k8s.io API.
+// The reader should not be cached, e.g. the manager's API reader.
+func NewMetricsAPISource(reader client.Reader) LoadProvider {
+	return &metricsAPISource{reader: reader}
+}
+
+// Load reports the usage of the configured resource, with each node's allocatable as its capacity
+func (m *metricsAPISource) Load(ctx context.Context, nodes []corev1.Node, settings *korev1alpha1.LoadBalancing) (*LoadReport, error) {
+	nodeUsage, err := m.nodeUsage(ctx)
+	if err != nil {
+		return nil, err
+	}
+	podUsage, err := m.podUsage(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &LoadReport{
+		Nodes:    make(map[string]float64, len(nodeUsage)),
+		Pods:     make(map[types.NamespacedName]float64, len(podUsage)),
+		Capacity: make(map[string]float64, len(nodes)),
+	}
+	for _, node := range nodes {
+		if allocatable, ok := node.Status.Allocatable[settings.Resource]; ok {
+			report.Capacity[node.Name] = float64(allocatable.MilliValue())
+		}
+	}
+	for name, usage := range nodeUsage {
+		if quantity, ok := usage[settings.Resource]; ok {
+			report.Nodes[name] = float64(quantity.MilliValue())
+		}
+	}
+	for key, usage := range podUsage {
+		if quantity, ok := usage[settings.Resource]; ok {
+			report.Pods[key] = float64(quantity.MilliValue())
+		}
+	}
+	return report, nil
+}
+
+// nodeUsage returns the usage of each node by node name
+func (m *metricsAPISource) nodeUsage(ctx context.Context) (map[string]corev1.ResourceList, error) {
+	list, err := m.list(ctx, "NodeMetricsList")
+	if err != nil {
+		return nil, err
+	}
+
+	usage := make(map[string]corev1.ResourceList, len(list.Items))
+	for _, item := range list.Items {
+		raw, _, _ := unstructured.NestedStringMap(item.Object, "usage")
+		resources, err := parseResourceList(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid usage for node %s: %w", item.GetName(), err)
+		}
+		usage[item.GetName()] = resources
+	}
+	return usage, nil
+}
+
+// podUsage returns the summed container usage of each pod
+func (m *metricsAPISource) podUsage(ctx context.Context) (map[types.NamespacedName]corev1.ResourceList, error) {
+	list, err := m.list(ctx, "PodMetricsList")
+	if err != nil {
+		return nil, err
+	}
+
+	usage := make(map[types.NamespacedName]corev1.ResourceList, len(list.Items))
+	for _, item := range list.Items {
+		key := types.NamespacedName{Namespace: item.GetNamespace(), Name: item.GetName()}
+		containers, _, _ := unstructured.NestedSlice(item.Object, "containers")
+
+		total := corev1.ResourceList{}
+		for _, c := range containers {
+			container, ok := c.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			raw, _, _ := unstructured.NestedStringMap(container, "usage")
+			resources, err := parseResourceList(raw)
+			if err != nil {
+				return nil, fmt.Errorf("invalid usage for pod %s: %w", key, err)
+			}
+			for name, quantity := range resources {
+				sum := total[name]
+				sum.Add(quantity)
+				total[name] = sum
+			}
+		}
+		usage[key] = total
+	}
+	return usage, nil
+}
+
+// list reads a metrics list, mapping a missing API to ErrLoadUnavailable
+func (m *metricsAPISource) list(ctx context.Context, kind string) (*unstructured.UnstructuredList, error) {
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(metricsGroupVersion.WithKind(kind))
+	if err := m.reader.List(ctx, list); err != nil {
+		if meta.IsNoMatchError(err) || apierrors.IsNotFound(err) || apierrors.IsServiceUnavailable(err) {
+			return nil, fmt.Errorf("%w: %v", ErrLoadUnavailable, err)
+		}
+		return nil, err
+	}
+	return list, nil
+}
+
+// parseResourceList converts a map of quantity strings into a ResourceList
+func parseResourceList(raw map[string]string) (corev1.ResourceList, error) {
+	resources := make(corev1.ResourceList, len(raw))
+	for name, value := range raw {
+		quantity, err := resource.ParseQuantity(value)
+		if err != nil {
+			return nil, err
+		}
+		resources[corev1.ResourceName(name)] = quantity
+	}
+	return resources, nil
+}
@@ -0,0 +1,220 @@
+package rebalancer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	korev1alpha1 "github.com/cxfcxf/pod-rebalancer/api/v1alpha1"
+)
+
+// testScheme knows the built-in types, RebalanceRequests and the metrics.k8s.io kinds,
+// which the fake client stores as unstructured objects
+func testScheme(t *testing.T) *runtime.Scheme {
+	t.Helper()
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := korev1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	for _, kind := range []string{"NodeMetrics", "PodMetrics"} {
+		scheme.AddKnownTypeWithName(metricsGroupVersion.WithKind(kind), &unstructured.Unstructured{})
+		scheme.AddKnownTypeWithName(metricsGroupVersion.WithKind(kind+"List"), &unstructured.UnstructuredList{})
+	}
+	return scheme
+}
+
+// testNode returns a ready node with the given allocatable CPU
+func testNode(name, cpu string) *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Status: corev1.NodeStatus{
+			Allocatable: corev1.ResourceList{
+				corev1.ResourceCPU:  resource.MustParse(cpu),
+				corev1.ResourcePods: resource.MustParse("110"),
+			},
+			Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionTrue}},
+		},
+	}
+}
+
+// testPod returns a running, opted-in pod owned by a ReplicaSet. Pods created later are newer.
+func testPod(name, node string, age time.Duration) *corev1.Pod {
+	controller := true
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         "shop",
+			Labels:            map[string]string{RebalanceEnabledLabel: "true"},
+			CreationTimestamp: metav1.NewTime(time.Now().Add(-age)),
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion: "apps/v1",
+				Kind:       "ReplicaSet",
+				Name:       "web",
+				Controller: &controller,
+			}},
+		},
+		Spec:   corev1.PodSpec{NodeName: node},
+		Status: corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+}
+
+// nodeMetrics returns a NodeMetrics object reporting the given CPU usage
+func nodeMetrics(name, cpu string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"metadata": map[string]interface{}{"name": name},
+		"usage":    map[string]interface{}{"cpu": cpu},
+	}}
+	obj.SetGroupVersionKind(metricsGroupVersion.WithKind("NodeMetrics"))
+	return obj
+}
+
+// podMetrics returns a PodMetrics object whose single container uses the given CPU
+func podMetrics(name, cpu string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"metadata": map[string]interface{}{"name": name, "namespace": "shop"},
+		"containers": []interface{}{
+			map[string]interface{}{"name": "app", "usage": map[string]interface{}{"cpu": cpu}},
+		},
+	}}
+	obj.SetGroupVersionKind(metricsGroupVersion.WithKind("PodMetrics"))
+	return obj
+}
+
+// testEngine builds an engine over a fake client holding the objects, with the metrics.k8s.io source
+func testEngine(t *testing.T, funcs *interceptor.Funcs, objects ...client.Object) *Engine {
+	t.Helper()
+	builder := fake.NewClientBuilder().
+		WithScheme(testScheme(t)).
+		WithObjects(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "shop"}}).
+		WithObjects(objects...).
+		WithIndex(&corev1.Pod{}, PodNodeNameField, PodNodeName)
+	if funcs != nil {
+		builder = builder.WithInterceptorFuncs(*funcs)
+	}
+	c := builder.Build()
+
+	e := NewEngine(c)
+	e.LoadProviders = map[korev1alpha1.LoadSource]LoadProvider{
+		korev1alpha1.LoadSourceMetrics: NewMetricsAPISource(c),
+	}
+	return e
+}
+
+// loadRequest returns a request balancing CPU usage from the metrics.k8s.io API without smoothing
+func loadRequest(name string) *korev1alpha1.RebalanceRequest {
+	return &korev1alpha1.RebalanceRequest{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Spec: korev1alpha1.RebalanceRequestSpec{
+			Strategy: korev1alpha1.BalanceStrategyLoad,
+			Load:     &korev1alpha1.LoadBalancing{Samples: 1},
+		},
+	}
+}
+
+func victimNames(plan *Plan) []string {
+	names := make([]string, 0, len(plan.Victims))
+	for _, pod := range plan.Victims {
+		names = append(names, pod.Name)
+	}
+	return names
+}
+
+func TestMetricsAPISourceEvictsHeaviestPodsFromHotNode(t *testing.T) {
+	e := testEngine(t, nil,
+		testNode("a", "1"), testNode("b", "1"),
+		testPod("heavy", "a", 3*time.Hour), testPod("medium", "a", 2*time.Hour), testPod("light", "a", time.Hour),
+		testPod("idle", "b", time.Hour),
+		nodeMetrics("a", "900m"), nodeMetrics("b", "100m"),
+		podMetrics("heavy", "500m"), podMetrics("medium", "300m"), podMetrics("light", "100m"),
+		podMetrics("idle", "100m"),
+	)
+
+	plan, err := e.Plan(context.Background(), loadRequest("shop"))
+	if err != nil {
+		t.Fatalf("Plan() error = %v", err)
+	}
+	if !plan.ByLoad {
+		t.Fatalf("Plan() balanced by pod count, want by load: %s", plan.Message)
+	}
+	// a runs at 90% against an average of 50%; moving the 50% pod brings it within the 10% threshold
+	if got := strings.Join(victimNames(plan), ","); got != "heavy" {
+		t.Errorf("victims = %s, want heavy", got)
+	}
+	for _, nc := range plan.Nodes {
+		if nc.TargetSource != TargetSourceLoad {
+			t.Errorf("node %s target source = %s, want %s", nc.NodeName, nc.TargetSource, TargetSourceLoad)
+		}
+	}
+}
+
+func TestMetricsAPIUnavailableFallsBackToPodCount(t *testing.T) {
+	// A cluster without metrics-server has no mapping for the metrics.k8s.io kinds
+	funcs := &interceptor.Funcs{
+		List: func(ctx context.Context, c client.WithWatch, list client.ObjectList, opts ...client.ListOption) error {
+			if u, ok := list.(*unstructured.UnstructuredList); ok && u.GroupVersionKind().Group == metricsGroupVersion.Group {
+				return &meta.NoKindMatchError{GroupKind: u.GroupVersionKind().GroupKind(), SearchedVersions: []string{metricsGroupVersion.Version}}
+			}
+			return c.List(ctx, list, opts...)
+		},
+	}
+	e := testEngine(t, funcs,
+		testNode("a", "1"), testNode("b", "1"),
+		testPod("oldest", "a", 4*time.Hour), testPod("old", "a", 3*time.Hour),
+		testPod("new", "a", 2*time.Hour), testPod("newest", "a", time.Hour),
+	)
+
+	plan, err := e.Plan(context.Background(), loadRequest("shop"))
+	if err != nil {
+		t.Fatalf("Plan() error = %v", err)
+	}
+	if plan.ByLoad {
+		t.Fatal("Plan() balanced by load without a metrics API")
+	}
+	// 4 pods over 2 nodes give a target of 3, so a gives up its newest pod
+	if got := strings.Join(victimNames(plan), ","); got != "newest" {
+		t.Errorf("victims = %s, want newest", got)
+	}
+}
+
+func TestLoadSamplesAreKeptPerRequest(t *testing.T) {
+	e := testEngine(t, nil,
+		testNode("a", "1"), testNode("b", "1"),
+		testPod("heavy", "a", time.Hour), testPod("idle", "b", time.Hour),
+		nodeMetrics("a", "900m"), nodeMetrics("b", "100m"),
+		podMetrics("heavy", "500m"), podMetrics("idle", "100m"),
+	)
+
+	for _, name := range []string{"first", "second"} {
+		if _, err := e.Plan(context.Background(), loadRequest(name)); err != nil {
+			t.Fatalf("Plan(%s) error = %v", name, err)
+		}
+	}
+	for _, name := range []string{"first", "second"} {
+		key := fmt.Sprintf("node/default/%s/%s/a", name, loadSignal(&korev1alpha1.LoadBalancing{
+			Source: korev1alpha1.LoadSourceMetrics, Resource: corev1.ResourceCPU,
+		}))
+		series, ok := e.samples.series[key]
+		if !ok {
+			t.Fatalf("no samples for %s", key)
+		}
+		if len(series.values) != 1 {
+			t.Errorf("%s has %d samples, want 1", key, len(series.values))
+		}
+	}
+}
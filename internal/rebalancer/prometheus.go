@@ -0,0 +1,153 @@
+package rebalancer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	korev1alpha1 "github.com/cxfcxf/pod-rebalancer/api/v1alpha1"
+)
+
+// Labels that key PromQL results to pods and nodes
+const (
+	PrometheusNamespaceLabel = "namespace"
+	PrometheusPodLabel       = "pod"
+	PrometheusNodeLabel      = "node"
+)
+
+// prometheusProvider runs instant PromQL queries against the Prometheus HTTP API
+type prometheusProvider struct {
+	address string
+	client  *http.Client
+}
+
+// prometheusResponse is the subset of the /api/v1/query response that is used
+type prometheusResponse struct {
+	Status    string `json:"status"`
+	ErrorType string `json:"errorType"`
+	Error     string `json:"error"`
+	Data      struct {
+		ResultType string `json:"resultType"`
+		Result     []struct {
+			Metric map[string]string `json:"metric"`
+			Value  []interface{}     `json:"value"`
+		} `json:"result"`
+	} `json:"data"`
+}
+
+// prometheusSample is one element of an instant vector
+type prometheusSample struct {
+	labels map[string]string
+	value  float64
+}
+
+// NewPrometheusProvider creates a LoadProvider that queries the Prometheus server at address.
+// Pod results are keyed by their namespace and pod labels and node results by their node label.
+func NewPrometheusProvider(address string, httpClient *http.Client) LoadProvider {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &prometheusProvider{
+		address: strings.TrimSuffix(address, "/"),
+		client:  httpClient,
+	}
+}
+
+// Load runs the request's pod query and, if set, its node query
+func (p *prometheusProvider) Load(ctx context.Context, _ []corev1.Node, settings *korev1alpha1.LoadBalancing) (*LoadReport, error) {
+	if settings.Prometheus == nil || settings.Prometheus.PodQuery == "" {
+		return nil, errors.New("prometheus load source requires a pod query")
+	}
+
+	samples, err := p.query(ctx, settings.Prometheus.PodQuery)
+	if err != nil {
+		return nil, fmt.Errorf("pod query: %w", err)
+	}
+	report := &LoadReport{
+		Pods: make(map[types.NamespacedName]float64, len(samples)),
+	}
+	for _, sample := range samples {
+		key := types.NamespacedName{
+			Namespace: sample.labels[PrometheusNamespaceLabel],
+			Name:      sample.labels[PrometheusPodLabel],
+		}
+		if key.Namespace == "" || key.Name == "" {
+			continue
+		}
+		report.Pods[key] += sample.value
+	}
+
+	if settings.Prometheus.NodeQuery == "" {
+		report.NodeLoadFromPods = true
+		return report, nil
+	}
+
+	samples, err = p.query(ctx, settings.Prometheus.NodeQuery)
+	if err != nil {
+		return nil, fmt.Errorf("node query: %w", err)
+	}
+	report.Nodes = make(map[string]float64, len(samples))
+	for _, sample := range samples {
+		if node := sample.labels[PrometheusNodeLabel]; node != "" {
+			report.Nodes[node] += sample.value
+		}
+	}
+	return report, nil
+}
+
+// query runs an instant query. An unreachable or failing server is reported as ErrLoadUnavailable,
+// while a query the server rejects is returned as a plain error.
+func (p *prometheusProvider) query(ctx context.Context, promql string) ([]prometheusSample, error) {
+	endpoint := p.address + "/api/v1/query?" + url.Values{"query": {promql}}.Encode()
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := p.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrLoadUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return nil, fmt.Errorf("%w: prometheus returned %s", ErrLoadUnavailable, resp.Status)
+	}
+
+	var body prometheusResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("invalid prometheus response (%s): %w", resp.Status, err)
+	}
+	if body.Status != "success" {
+		return nil, fmt.Errorf("prometheus query failed: %s: %s", body.ErrorType, body.Error)
+	}
+	if body.Data.ResultType != "vector" {
+		return nil, fmt.Errorf("prometheus query returned %s, expected vector", body.Data.ResultType)
+	}
+
+	samples := make([]prometheusSample, 0, len(body.Data.Result))
+	for _, result := range body.Data.Result {
+		// Instant vector values are [timestamp, "value"]
+		if len(result.Value) != 2 {
+			continue
+		}
+		raw, ok := result.Value[1].(string)
+		if !ok {
+			continue
+		}
+		value, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid sample value %q: %w", raw, err)
+		}
+		samples = append(samples, prometheusSample{labels: result.Metric, value: value})
+	}
+	return samples, nil
+}
@@ -0,0 +1,179 @@
+package rebalancer
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+
+	korev1alpha1 "github.com/cxfcxf/pod-rebalancer/api/v1alpha1"
+)
+
+// prometheusStub serves canned /api/v1/query responses keyed by the PromQL query
+func prometheusStub(t *testing.T, responses map[string]string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/query" {
+			http.NotFound(w, r)
+			return
+		}
+		body, ok := responses[r.URL.Query().Get("query")]
+		if !ok {
+			t.Errorf("unexpected query %q", r.URL.Query().Get("query"))
+			http.Error(w, "unexpected query", http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func prometheusSettings(podQuery, nodeQuery string) *korev1alpha1.LoadBalancing {
+	return &korev1alpha1.LoadBalancing{
+		Source:     korev1alpha1.LoadSourcePrometheus,
+		Prometheus: &korev1alpha1.PrometheusLoad{PodQuery: podQuery, NodeQuery: nodeQuery},
+	}
+}
+
+func TestPrometheusProviderParsesInstantVectors(t *testing.T) {
+	server := prometheusStub(t, map[string]string{
+		"pod_cpu": `{"status":"success","data":{"resultType":"vector","result":[
+			{"metric":{"namespace":"shop","pod":"web-1"},"value":[1700000000,"0.5"]},
+			{"metric":{"namespace":"shop","pod":"web-1","container":"sidecar"},"value":[1700000000,"0.25"]},
+			{"metric":{"namespace":"shop","pod":"web-2"},"value":[1700000000,"1"]},
+			{"metric":{"pod":"no-namespace"},"value":[1700000000,"9"]},
+			{"metric":{"namespace":"shop"},"value":[1700000000,"9"]}
+		]}}`,
+		"node_cpu": `{"status":"success","data":{"resultType":"vector","result":[
+			{"metric":{"node":"a"},"value":[1700000000,"2"]},
+			{"metric":{"instance":"10.0.0.1"},"value":[1700000000,"9"]}
+		]}}`,
+	})
+
+	report, err := NewPrometheusProvider(server.URL, nil).Load(context.Background(), nil, prometheusSettings("pod_cpu", "node_cpu"))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	// Series of the same pod are summed, series missing the namespace or pod label are dropped
+	wantPods := map[types.NamespacedName]float64{
+		{Namespace: "shop", Name: "web-1"}: 0.75,
+		{Namespace: "shop", Name: "web-2"}: 1,
+	}
+	if len(report.Pods) != len(wantPods) {
+		t.Errorf("pods = %v, want %v", report.Pods, wantPods)
+	}
+	for key, want := range wantPods {
+		if got := report.Pods[key]; got != want {
+			t.Errorf("pod %s load = %v, want %v", key, got, want)
+		}
+	}
+	// Series missing the node label are dropped
+	if len(report.Nodes) != 1 || report.Nodes["a"] != 2 {
+		t.Errorf("nodes = %v, want a=2", report.Nodes)
+	}
+	if report.NodeLoadFromPods {
+		t.Error("NodeLoadFromPods set although a node query was given")
+	}
+	if report.Capacity != nil {
+		t.Errorf("capacity = %v, want none", report.Capacity)
+	}
+}
+
+func TestPrometheusProviderSumsPodsWithoutNodeQuery(t *testing.T) {
+	server := prometheusStub(t, map[string]string{
+		"pod_cpu": `{"status":"success","data":{"resultType":"vector","result":[
+			{"metric":{"namespace":"shop","pod":"web-1"},"value":[1700000000,"0.5"]}
+		]}}`,
+	})
+
+	report, err := NewPrometheusProvider(server.URL, nil).Load(context.Background(), nil, prometheusSettings("pod_cpu", ""))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if !report.NodeLoadFromPods {
+		t.Error("NodeLoadFromPods not set without a node query")
+	}
+}
+
+func TestPrometheusProviderRejectedQuery(t *testing.T) {
+	server := prometheusStub(t, map[string]string{
+		"rate(": `{"status":"error","errorType":"bad_data","error":"parse error: unclosed left parenthesis"}`,
+	})
+
+	_, err := NewPrometheusProvider(server.URL, nil).Load(context.Background(), nil, prometheusSettings("rate(", ""))
+	if err == nil {
+		t.Fatal("Load() succeeded for a rejected query")
+	}
+	// A query the server rejects is a configuration error, not an outage to fall back from
+	if errors.Is(err, ErrLoadUnavailable) {
+		t.Errorf("Load() error = %v, want an error not wrapping ErrLoadUnavailable", err)
+	}
+}
+
+func TestPrometheusProviderUnavailable(t *testing.T) {
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	t.Cleanup(slow.Close)
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "overloaded", http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(failing.Close)
+
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+
+	tests := []struct {
+		name    string
+		address string
+	}{
+		{"timeout", slow.URL},
+		{"server error", failing.URL},
+		{"connection refused", closed.URL},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := NewPrometheusProvider(tt.address, &http.Client{Timeout: 100 * time.Millisecond})
+			_, err := provider.Load(context.Background(), nil, prometheusSettings("pod_cpu", ""))
+			if !errors.Is(err, ErrLoadUnavailable) {
+				t.Errorf("Load() error = %v, want it to wrap ErrLoadUnavailable", err)
+			}
+		})
+	}
+}
+
+func TestPrometheusUnavailableFallsBackToPodCount(t *testing.T) {
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+
+	e := testEngine(t, nil,
+		testNode("a", "1"), testNode("b", "1"),
+		testPod("oldest", "a", 4*time.Hour), testPod("old", "a", 3*time.Hour),
+		testPod("new", "a", 2*time.Hour), testPod("newest", "a", time.Hour),
+	)
+	e.LoadProviders[korev1alpha1.LoadSourcePrometheus] = NewPrometheusProvider(closed.URL, &http.Client{Timeout: time.Second})
+	req := loadRequest("shop")
+	req.Spec.Load = prometheusSettings("pod_cpu", "")
+
+	plan, err := e.Plan(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Plan() error = %v", err)
+	}
+	if plan.ByLoad {
+		t.Fatal("Plan() balanced by load with Prometheus unreachable")
+	}
+	if len(plan.Victims) != 1 || plan.Victims[0].Name != "newest" {
+		t.Errorf("victims = %v, want newest", victimNames(plan))
+	}
+}
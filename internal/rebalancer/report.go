@@ -0,0 +1,135 @@
+package rebalancer
+
+import (
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	korev1alpha1 "github.com/cxfcxf/pod-rebalancer/api/v1alpha1"
+)
+
+// maxReportSkipped bounds the skipped pods listed in a report; the rest are only counted
+const maxReportSkipped = 100
+
+// Report is the machine-readable record of one run
+type Report struct {
+	Request   string    `json:"request"`
+	Run       int32     `json:"run"`
+	Trigger   string    `json:"trigger"`
+	StartTime time.Time `json:"startTime"`
+	EndTime   time.Time `json:"endTime"`
+
+	// Spec is the request spec the run used
+	Spec korev1alpha1.RebalanceRequestSpec `json:"spec"`
+
+	TotalPods int            `json:"totalPods"`
+	ByLoad    bool           `json:"byLoad,omitempty"`
+	Nodes     []ReportNode   `json:"nodes"`
+	Victims   []ReportVictim `json:"victims"`
+	Skipped   []ReportSkip   `json:"skipped,omitempty"`
+	// SkippedByReason counts every skipped pod, including those beyond the listed ones
+	SkippedByReason map[SkipReason]int `json:"skippedByReason,omitempty"`
+
+	Evicted int32  `json:"evicted"`
+	Tripped string `json:"tripped,omitempty"`
+	Error   string `json:"error,omitempty"`
+	Message string `json:"message"`
+}
+
+// ReportNode is one row of the run's node table
+type ReportNode struct {
+	Name               string `json:"name"`
+	PodCount           int    `json:"podCount"`
+	UnmanagedPods      int    `json:"unmanagedPods,omitempty"`
+	MaxPods            int    `json:"maxPods,omitempty"`
+	Target             int    `json:"target"`
+	TargetSource       string `json:"targetSource"`
+	UnderPressure      bool   `json:"underPressure,omitempty"`
+	UtilizationPercent int    `json:"utilizationPercent,omitempty"`
+}
+
+// ReportVictim is a pod the run chose to evict
+type ReportVictim struct {
+	Pod      string `json:"pod"`
+	Node     string `json:"node"`
+	Workload string `json:"workload"`
+	// Evicted is false when the run stopped early, the eviction failed, or the run was a dry run
+	Evicted bool `json:"evicted"`
+}
+
+// ReportSkip is a managed pod the run did not consider
+type ReportSkip struct {
+	Pod    string     `json:"pod"`
+	Reason SkipReason `json:"reason"`
+}
+
+// NewReport builds the report of a run of the request
+func NewReport(req *korev1alpha1.RebalanceRequest, run int32, trigger string, start, end time.Time, result RebalanceResult) *Report {
+	report := &Report{
+		Request:   req.Namespace + "/" + req.Name,
+		Run:       run,
+		Trigger:   trigger,
+		StartTime: start,
+		EndTime:   end,
+		Spec:      req.Spec,
+		TotalPods: int(result.TotalPods),
+		Nodes:     make([]ReportNode, 0, len(result.Nodes)),
+		Victims:   []ReportVictim{},
+		Evicted:   result.PodsEvicted,
+		Tripped:   result.Tripped,
+		Message:   result.Message,
+	}
+	if result.Error != nil {
+		report.Error = result.Error.Error()
+	}
+
+	for _, nc := range result.Nodes {
+		node := ReportNode{
+			Name:          nc.NodeName,
+			PodCount:      nc.PodCount,
+			UnmanagedPods: nc.Unmanaged,
+			Target:        nc.Target,
+			TargetSource:  nc.TargetSource,
+			UnderPressure: nc.Pressure,
+		}
+		if nc.MaxPods > 0 {
+			node.MaxPods = nc.MaxPods
+		}
+		if nc.TargetSource == TargetSourceLoad {
+			node.UtilizationPercent = int(nc.Utilization * 100)
+		}
+		report.Nodes = append(report.Nodes, node)
+	}
+
+	if plan := result.Plan; plan != nil {
+		report.ByLoad = plan.ByLoad
+		evicted := make(map[string]bool, len(result.Evictions))
+		for _, eviction := range result.Evictions {
+			evicted[eviction.Namespace+"/"+eviction.Name] = true
+		}
+		for i := range plan.Victims {
+			pod := &plan.Victims[i]
+			key := client.ObjectKeyFromObject(pod)
+			report.Victims = append(report.Victims, ReportVictim{
+				Pod:      key.String(),
+				Node:     pod.Spec.NodeName,
+				Workload: plan.Workloads[key].String(),
+				Evicted:  evicted[key.String()],
+			})
+		}
+	}
+
+	if len(result.Skipped) > 0 {
+		report.SkippedByReason = make(map[SkipReason]int)
+		for _, skipped := range result.Skipped {
+			report.SkippedByReason[skipped.Reason]++
+			if len(report.Skipped) < maxReportSkipped {
+				report.Skipped = append(report.Skipped, ReportSkip{
+					Pod:    skipped.Namespace + "/" + skipped.Name,
+					Reason: skipped.Reason,
+				})
+			}
+		}
+	}
+	return report
+}
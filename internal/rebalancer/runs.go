@@ -0,0 +1,108 @@
+package rebalancer
+
+import (
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// RunState is the progress of a request's current or last run
+type RunState struct {
+	Running   bool
+	StartTime time.Time
+	EndTime   time.Time
+	Batch     int // Batches started so far
+	Batches   int
+	Evicted   int32
+	Message   string
+	// Plan is what the run decided to evict, nil until planning finished
+	Plan *Plan
+}
+
+// RunTracker records the state of each request's runs for inspection while they happen.
+// A nil tracker records nothing.
+type RunTracker struct {
+	mu   sync.Mutex
+	runs map[types.NamespacedName]*RunState
+}
+
+// NewRunTracker returns an empty tracker
+func NewRunTracker() *RunTracker {
+	return &RunTracker{runs: make(map[types.NamespacedName]*RunState)}
+}
+
+// Get returns a copy of the request's run state
+func (t *RunTracker) Get(key types.NamespacedName) (RunState, bool) {
+	if t == nil {
+		return RunState{}, false
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	state, ok := t.runs[key]
+	if !ok {
+		return RunState{}, false
+	}
+	return *state, true
+}
+
+// Forget drops the state of a deleted request
+func (t *RunTracker) Forget(key types.NamespacedName) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.runs, key)
+}
+
+// update applies fn to the request's run state under the lock
+func (t *RunTracker) update(key types.NamespacedName, fn func(state *RunState)) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	state, ok := t.runs[key]
+	if !ok {
+		state = &RunState{}
+		t.runs[key] = state
+	}
+	fn(state)
+}
+
+func (t *RunTracker) begin(key types.NamespacedName) {
+	t.update(key, func(state *RunState) {
+		*state = RunState{Running: true, StartTime: time.Now(), Message: "Planning"}
+	})
+}
+
+func (t *RunTracker) planned(key types.NamespacedName, plan *Plan, batches int) {
+	t.update(key, func(state *RunState) {
+		state.Plan = plan
+		state.Batches = batches
+		state.Message = "Evicting"
+	})
+}
+
+func (t *RunTracker) progress(key types.NamespacedName, batch int, evicted int32) {
+	t.update(key, func(state *RunState) {
+		state.Batch = batch
+		state.Evicted = evicted
+	})
+}
+
+func (t *RunTracker) end(key types.NamespacedName, result RebalanceResult) {
+	t.update(key, func(state *RunState) {
+		state.Running = false
+		state.EndTime = time.Now()
+		state.Evicted = result.PodsEvicted
+		state.Plan = result.Plan
+		state.Message = result.Message
+		if result.Error != nil {
+			state.Message = result.Error.Error()
+		}
+	})
+}
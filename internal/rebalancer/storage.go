@@ -0,0 +1,74 @@
+package rebalancer
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	korev1alpha1 "github.com/cxfcxf/pod-rebalancer/api/v1alpha1"
+)
+
+// getLocalStorageSkipReason checks a pod's volumes against the local storage policy.
+// Returns an empty reason if the pod may be evicted.
+func (e *Engine) getLocalStorageSkipReason(ctx context.Context, pod *corev1.Pod, policy *korev1alpha1.LocalStoragePolicy) (SkipReason, error) {
+	if policy == nil {
+		policy = &korev1alpha1.LocalStoragePolicy{}
+	}
+
+	for _, vol := range pod.Spec.Volumes {
+		switch {
+		case vol.EmptyDir != nil:
+			if vol.EmptyDir.Medium == corev1.StorageMediumMemory {
+				if !policy.AllowMemoryEmptyDir {
+					return SkipReasonMemoryEmptyDir, nil
+				}
+				continue
+			}
+			// Disk-backed emptyDir is only allowed when its size is bounded below the policy maximum
+			sizeLimit := vol.EmptyDir.SizeLimit
+			if policy.MaxEmptyDirSize == nil || sizeLimit == nil || sizeLimit.Cmp(*policy.MaxEmptyDirSize) > 0 {
+				return SkipReasonEmptyDir, nil
+			}
+
+		case vol.HostPath != nil:
+			if !policy.AllowHostPath {
+				return SkipReasonHostPath, nil
+			}
+
+		case vol.PersistentVolumeClaim != nil, vol.Ephemeral != nil:
+			if policy.AllowLocalPersistentVolumes {
+				continue
+			}
+			claimName := pod.Name + "-" + vol.Name
+			if vol.PersistentVolumeClaim != nil {
+				claimName = vol.PersistentVolumeClaim.ClaimName
+			}
+			local, err := e.isLocalVolumeClaim(ctx, pod.Namespace, claimName)
+			if err != nil {
+				return "", err
+			}
+			if local {
+				return SkipReasonLocalPersistentVolume, nil
+			}
+		}
+	}
+	return "", nil
+}
+
+// isLocalVolumeClaim checks if a claim is bound to a node-local PersistentVolume
+func (e *Engine) isLocalVolumeClaim(ctx context.Context, namespace, name string) (bool, error) {
+	var pvc corev1.PersistentVolumeClaim
+	if err := e.Client.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, &pvc); err != nil {
+		return false, client.IgnoreNotFound(err)
+	}
+	if pvc.Spec.VolumeName == "" {
+		return false, nil
+	}
+
+	var pv corev1.PersistentVolume
+	if err := e.Client.Get(ctx, client.ObjectKey{Name: pvc.Spec.VolumeName}, &pv); err != nil {
+		return false, client.IgnoreNotFound(err)
+	}
+	return pv.Spec.Local != nil || pv.Spec.HostPath != nil, nil
+}
@@ -0,0 +1,224 @@
+package rebalancer
+
+import (
+	"fmt"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	korev1alpha1 "github.com/cxfcxf/pod-rebalancer/api/v1alpha1"
+)
+
+// compiledNodeTarget is a NodeTarget with its selectors parsed into one label selector
+type compiledNodeTarget struct {
+	korev1alpha1.NodeTarget
+	selector    labels.Selector
+	specificity int
+}
+
+// compileNodeTargets parses the targets' selectors and orders them by precedence:
+// highest priority first, then most specific, then list order
+func compileNodeTargets(nodeTargets []korev1alpha1.NodeTarget) ([]compiledNodeTarget, error) {
+	compiled := make([]compiledNodeTarget, 0, len(nodeTargets))
+	for i, target := range nodeTargets {
+		if target.MaxPodsPerNode <= 0 && target.Capacity == nil {
+			return nil, fmt.Errorf("nodeTargets[%d] needs maxPodsPerNode or capacity", i)
+		}
+
+		// NodeSelector and Selector are ANDed into a single selector
+		combined := &metav1.LabelSelector{MatchLabels: map[string]string{}}
+		for key, value := range target.NodeSelector {
+			combined.MatchLabels[key] = value
+		}
+		if target.Selector != nil {
+			for key, value := range target.Selector.MatchLabels {
+				combined.MatchLabels[key] = value
+			}
+			combined.MatchExpressions = target.Selector.MatchExpressions
+		}
+
+		selector, err := metav1.LabelSelectorAsSelector(combined)
+		if err != nil {
+			return nil, fmt.Errorf("invalid selector in nodeTargets[%d]: %w", i, err)
+		}
+		requirements, _ := selector.Requirements()
+		compiled = append(compiled, compiledNodeTarget{
+			NodeTarget:  target,
+			selector:    selector,
+			specificity: len(requirements),
+		})
+	}
+
+	sort.SliceStable(compiled, func(i, j int) bool {
+		if compiled[i].Priority != compiled[j].Priority {
+			return compiled[i].Priority > compiled[j].Priority
+		}
+		return compiled[i].specificity > compiled[j].specificity
+	})
+	return compiled, nil
+}
+
+// getMaxPodsForNode returns the maximum pod count for a node based on nodeTargets.
+// Returns -1 if there are no targets (will use average later), and false if targets
+// exist but none match the node. A matched node that fits no pods gets a maximum of 0.
+// managedProfile is the per-pod request profile of the managed pods, used by targets
+// that derive capacity from them.
+func (e *Engine) getMaxPodsForNode(node *corev1.Node, nodeTargets []compiledNodeTarget, managedProfile corev1.ResourceList) (int, bool) {
+	if len(nodeTargets) == 0 {
+		return -1, true
+	}
+
+	for _, target := range nodeTargets {
+		if target.selector.Matches(labels.Set(node.Labels)) {
+			return target.maxPodsForNode(node, managedProfile), true
+		}
+	}
+	return 0, false
+}
+
+// maxPodsForNode returns the target's maximum for a node, deriving it from the node's
+// allocatable resources when Capacity is set. MaxPodsPerNode caps a derived value and
+// is used on its own when the profile has no requests; without either, only the node's
+// pod limit applies.
+func (t *compiledNodeTarget) maxPodsForNode(node *corev1.Node, managedProfile corev1.ResourceList) int {
+	maxPods := int(t.MaxPodsPerNode)
+	if t.Capacity == nil {
+		return maxPods
+	}
+
+	profile := t.Capacity.PodRequests
+	if t.Capacity.FromManagedPods {
+		profile = managedProfile
+	}
+	derived, ok := podsFittingNode(node, profile)
+	if !ok {
+		if maxPods > 0 {
+			return maxPods
+		}
+		return allocatablePods(node)
+	}
+	if maxPods > 0 && maxPods < derived {
+		return maxPods
+	}
+	return derived
+}
+
+// podsFittingNode returns how many pods with the given requests fit in the node's
+// allocatable resources, limited by the node's allocatable pod count.
+// Returns false if the profile has no non-zero requests.
+func podsFittingNode(node *corev1.Node, profile corev1.ResourceList) (int, bool) {
+	fits := -1
+	for name, request := range profile {
+		if request.IsZero() {
+			continue
+		}
+		count := 0
+		if allocatable, ok := node.Status.Allocatable[name]; ok {
+			count = int(allocatable.MilliValue() / request.MilliValue())
+		}
+		if fits < 0 || count < fits {
+			fits = count
+		}
+	}
+	if fits < 0 {
+		return 0, false
+	}
+
+	if podLimit, ok := node.Status.Allocatable[corev1.ResourcePods]; ok && int(podLimit.Value()) < fits {
+		fits = int(podLimit.Value())
+	}
+	return fits, true
+}
+
+// needsManagedProfile checks if any target derives capacity from the managed pods' requests
+func needsManagedProfile(nodeTargets []compiledNodeTarget) bool {
+	for _, target := range nodeTargets {
+		if target.Capacity != nil && target.Capacity.FromManagedPods {
+			return true
+		}
+	}
+	return false
+}
+
+// maxPodRequests returns the largest request for each resource across the pods
+func maxPodRequests(pods []corev1.Pod) corev1.ResourceList {
+	profile := corev1.ResourceList{}
+	for i := range pods {
+		for name, quantity := range podRequests(&pods[i]) {
+			if current, ok := profile[name]; !ok || quantity.Cmp(current) > 0 {
+				profile[name] = quantity
+			}
+		}
+	}
+	return profile
+}
+
+// podRequests returns the effective requests of a pod: the larger of the summed
+// app containers and any single init container, plus pod overhead
+func podRequests(pod *corev1.Pod) corev1.ResourceList {
+	requests := corev1.ResourceList{}
+	for _, container := range pod.Spec.Containers {
+		for name, quantity := range container.Resources.Requests {
+			total := requests[name]
+			total.Add(quantity)
+			requests[name] = total
+		}
+	}
+	for _, container := range pod.Spec.InitContainers {
+		for name, quantity := range container.Resources.Requests {
+			if current, ok := requests[name]; !ok || quantity.Cmp(current) > 0 {
+				requests[name] = quantity
+			}
+		}
+	}
+	for name, quantity := range pod.Spec.Overhead {
+		total := requests[name]
+		total.Add(quantity)
+		requests[name] = total
+	}
+	return requests
+}
+
+// DefaultUnmatchedMaxPods is the maximum of nodes matching no NodeTarget when the request
+// sets no other policy. It is high enough that pods may schedule on new node types without
+// being evicted at once.
+const DefaultUnmatchedMaxPods = 1000
+
+// applyUnmatchedNodePolicy assigns a maximum to nodes that match no NodeTarget.
+// Returns the nodes that take part in balancing; excluded nodes are dropped.
+func applyUnmatchedNodePolicy(matched, unmatched []NodePodCount, policy *korev1alpha1.UnmatchedNodePolicy) []NodePodCount {
+	if len(unmatched) == 0 {
+		return nil
+	}
+	if policy == nil {
+		policy = &korev1alpha1.UnmatchedNodePolicy{Mode: korev1alpha1.UnmatchedNodeFixed}
+	}
+
+	var maxPods int
+	switch policy.Mode {
+	case korev1alpha1.UnmatchedNodeFixed, "":
+		maxPods = int(policy.MaxPodsPerNode)
+		if maxPods <= 0 {
+			maxPods = DefaultUnmatchedMaxPods
+		}
+	case korev1alpha1.UnmatchedNodeAverage:
+		if len(matched) == 0 {
+			return nil
+		}
+		total := 0
+		for _, nc := range matched {
+			total += nc.MaxPods
+		}
+		maxPods = total / len(matched)
+	}
+	if maxPods <= 0 {
+		return nil
+	}
+
+	for i := range unmatched {
+		unmatched[i].MaxPods = maxPods
+	}
+	return unmatched
+}
@@ -0,0 +1,250 @@
+package rebalancer
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	korev1alpha1 "github.com/cxfcxf/pod-rebalancer/api/v1alpha1"
+)
+
+// labelledNode returns a ready node with the given labels and allocatable resources
+func labelledNode(name string, nodeLabels map[string]string, allocatable corev1.ResourceList) *corev1.Node {
+	node := testNode(name, "4")
+	node.Labels = nodeLabels
+	for resourceName, quantity := range allocatable {
+		node.Status.Allocatable[resourceName] = quantity
+	}
+	return node
+}
+
+func TestGetMaxPodsForNode(t *testing.T) {
+	gpuTarget := korev1alpha1.NodeTarget{
+		NodeSelector: map[string]string{"pool": "gpu"},
+		Capacity: &korev1alpha1.NodeCapacity{PodRequests: corev1.ResourceList{
+			"nvidia.com/gpu": resource.MustParse("1"),
+		}},
+	}
+	managedTarget := korev1alpha1.NodeTarget{
+		NodeSelector: map[string]string{"pool": "general"},
+		Capacity:     &korev1alpha1.NodeCapacity{FromManagedPods: true},
+	}
+	targets, err := compileNodeTargets([]korev1alpha1.NodeTarget{gpuTarget, managedTarget})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		node        *corev1.Node
+		profile     corev1.ResourceList
+		wantMaxPods int
+		wantMatched bool
+	}{
+		{
+			name:        "gpu node",
+			node:        labelledNode("gpu", map[string]string{"pool": "gpu"}, corev1.ResourceList{"nvidia.com/gpu": resource.MustParse("4")}),
+			wantMaxPods: 4,
+			wantMatched: true,
+		},
+		{
+			// The resource is missing from allocatable, e.g. before the device plugin starts
+			name:        "zero-fit node",
+			node:        labelledNode("no-gpu", map[string]string{"pool": "gpu"}, nil),
+			wantMaxPods: 0,
+			wantMatched: true,
+		},
+		{
+			// The managed pods request nothing, so only the node's pod limit applies
+			name:        "profile-less node",
+			node:        labelledNode("general", map[string]string{"pool": "general"}, nil),
+			profile:     corev1.ResourceList{},
+			wantMaxPods: 110,
+			wantMatched: true,
+		},
+		{
+			name:        "managed profile",
+			node:        labelledNode("general", map[string]string{"pool": "general"}, nil),
+			profile:     corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("500m")},
+			wantMaxPods: 8,
+			wantMatched: true,
+		},
+		{
+			name:        "unmatched node",
+			node:        labelledNode("other", map[string]string{"pool": "other"}, nil),
+			wantMaxPods: 0,
+			wantMatched: false,
+		},
+	}
+	e := &Engine{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			maxPods, matched := e.getMaxPodsForNode(tt.node, targets, tt.profile)
+			if maxPods != tt.wantMaxPods || matched != tt.wantMatched {
+				t.Errorf("getMaxPodsForNode() = %d, %v, want %d, %v", maxPods, matched, tt.wantMaxPods, tt.wantMatched)
+			}
+		})
+	}
+}
+
+func TestApplyUnmatchedNodePolicy(t *testing.T) {
+	matched := []NodePodCount{{NodeName: "big", MaxPods: 20}, {NodeName: "small", MaxPods: 10}}
+	tests := []struct {
+		name    string
+		matched []NodePodCount
+		policy  *korev1alpha1.UnmatchedNodePolicy
+		want    int // 0 means the unmatched nodes are dropped
+	}{
+		{name: "default", matched: matched, want: DefaultUnmatchedMaxPods},
+		{name: "fixed without a maximum", matched: matched, policy: &korev1alpha1.UnmatchedNodePolicy{Mode: korev1alpha1.UnmatchedNodeFixed}, want: DefaultUnmatchedMaxPods},
+		{name: "fixed", matched: matched, policy: &korev1alpha1.UnmatchedNodePolicy{Mode: korev1alpha1.UnmatchedNodeFixed, MaxPodsPerNode: 7}, want: 7},
+		{name: "average", matched: matched, policy: &korev1alpha1.UnmatchedNodePolicy{Mode: korev1alpha1.UnmatchedNodeAverage}, want: 15},
+		{name: "average without matched nodes", policy: &korev1alpha1.UnmatchedNodePolicy{Mode: korev1alpha1.UnmatchedNodeAverage}},
+		{name: "average of zero-fit nodes", matched: []NodePodCount{{NodeName: "full", MaxPods: 0}}, policy: &korev1alpha1.UnmatchedNodePolicy{Mode: korev1alpha1.UnmatchedNodeAverage}},
+		{name: "exclude", matched: matched, policy: &korev1alpha1.UnmatchedNodePolicy{Mode: korev1alpha1.UnmatchedNodeExclude}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			unmatched := []NodePodCount{{NodeName: "new-a"}, {NodeName: "new-b"}}
+			got := applyUnmatchedNodePolicy(tt.matched, unmatched, tt.policy)
+			if tt.want == 0 {
+				if len(got) != 0 {
+					t.Errorf("applyUnmatchedNodePolicy() kept %d nodes, want them dropped", len(got))
+				}
+				return
+			}
+			if len(got) != 2 {
+				t.Fatalf("applyUnmatchedNodePolicy() kept %d nodes, want 2", len(got))
+			}
+			for _, nc := range got {
+				if nc.MaxPods != tt.want {
+					t.Errorf("%s MaxPods = %d, want %d", nc.NodeName, nc.MaxPods, tt.want)
+				}
+			}
+		})
+	}
+	if got := applyUnmatchedNodePolicy(matched, nil, nil); got != nil {
+		t.Errorf("applyUnmatchedNodePolicy() without unmatched nodes = %v, want nil", got)
+	}
+}
+
+func TestCalculateTargets(t *testing.T) {
+	// 12 pods: big has 2, small has 10 and other, which no target matches, has none
+	web := func(maxBig, maxSmall int32) []korev1alpha1.NodeTarget {
+		return []korev1alpha1.NodeTarget{
+			{NodeSelector: map[string]string{"pool": "big"}, MaxPodsPerNode: maxBig},
+			{NodeSelector: map[string]string{"pool": "small"}, MaxPodsPerNode: maxSmall},
+		}
+	}
+	exclude := &korev1alpha1.UnmatchedNodePolicy{Mode: korev1alpha1.UnmatchedNodeExclude}
+	tests := []struct {
+		name      string
+		spec      korev1alpha1.RebalanceRequestSpec
+		unmanaged map[string]int
+		pressure  bool // big reports MemoryPressure
+		want      map[string]string
+		evicted   int
+	}{
+		{
+			name:    "average without targets",
+			want:    map[string]string{"big": "5 Average", "small": "5 Average", "other": "5 Average"},
+			evicted: 5,
+		},
+		{
+			// 12 pods over 30 slots: big 8 + 1, small 4 + 1
+			name:    "proportional",
+			spec:    korev1alpha1.RebalanceRequestSpec{NodeTargets: web(20, 10), UnmatchedNodes: exclude},
+			want:    map[string]string{"big": "9 Proportional", "small": "5 Proportional"},
+			evicted: 5,
+		},
+		{
+			// other takes the default 1000 and draws almost every pod
+			name:    "proportional with the unmatched default",
+			spec:    korev1alpha1.RebalanceRequestSpec{NodeTargets: web(20, 10)},
+			want:    map[string]string{"big": "1 Proportional", "small": "1 Proportional", "other": "12 Proportional"},
+			evicted: 10,
+		},
+		{
+			// other gets the average of 15
+			name: "proportional with unmatched average",
+			spec: korev1alpha1.RebalanceRequestSpec{NodeTargets: web(20, 10),
+				UnmatchedNodes: &korev1alpha1.UnmatchedNodePolicy{Mode: korev1alpha1.UnmatchedNodeAverage}},
+			want:    map[string]string{"big": "6 Proportional", "small": "3 Proportional", "other": "5 Proportional"},
+			evicted: 7,
+		},
+		{
+			name:    "hard cap",
+			spec:    korev1alpha1.RebalanceRequestSpec{NodeTargets: web(20, 10), UnmatchedNodes: exclude, TargetMode: korev1alpha1.TargetModeHardCap},
+			want:    map[string]string{"big": "20 HardCap", "small": "10 HardCap"},
+			evicted: 0,
+		},
+		{
+			name:    "both below capacity",
+			spec:    korev1alpha1.RebalanceRequestSpec{NodeTargets: web(20, 10), UnmatchedNodes: exclude, TargetMode: korev1alpha1.TargetModeBoth},
+			want:    map[string]string{"big": "9 Proportional", "small": "5 Proportional"},
+			evicted: 5,
+		},
+		{
+			// 12 pods over 10 slots: the shares of 8 and 5 exceed the maximums
+			name:    "both above capacity",
+			spec:    korev1alpha1.RebalanceRequestSpec{NodeTargets: web(6, 4), UnmatchedNodes: exclude, TargetMode: korev1alpha1.TargetModeBoth},
+			want:    map[string]string{"big": "6 HardCap", "small": "4 HardCap"},
+			evicted: 6,
+		},
+		{
+			// big keeps 20 - 15 = 5 slots, so 12 pods over 15: big 4 + 1, small 8 + 1
+			name: "unmanaged pods",
+			spec: korev1alpha1.RebalanceRequestSpec{NodeTargets: web(20, 10), UnmatchedNodes: exclude,
+				CapacityAccounting: &korev1alpha1.CapacityAccounting{UnmanagedPods: true}},
+			unmanaged: map[string]int{"big": 15},
+			want:      map[string]string{"big": "5 Proportional", "small": "9 Proportional"},
+			evicted:   1,
+		},
+		{
+			// Accounting without targets uses the pod limits: big is capped at its 2 pods,
+			// so 12 pods over 2 + 110 + 110 slots
+			name:     "node pressure",
+			spec:     korev1alpha1.RebalanceRequestSpec{CapacityAccounting: &korev1alpha1.CapacityAccounting{NodePressure: true}},
+			pressure: true,
+			want:     map[string]string{"big": "1 Proportional", "small": "6 Proportional", "other": "6 Proportional"},
+			evicted:  5,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			big := labelledNode("big", map[string]string{"pool": "big"}, nil)
+			if tt.pressure {
+				big.Status.Conditions = append(big.Status.Conditions, corev1.NodeCondition{Type: corev1.NodeMemoryPressure, Status: corev1.ConditionTrue})
+			}
+			nodes := []corev1.Node{*big, *labelledNode("small", map[string]string{"pool": "small"}, nil), *testNode("other", "4")}
+			var pods []corev1.Pod
+			for i := 0; i < 12; i++ {
+				node := "small"
+				if i < 2 {
+					node = "big"
+				}
+				pods = append(pods, *testPod(fmt.Sprintf("web-%d", i), node, time.Duration(i)*time.Minute))
+			}
+
+			e := testEngine(t, nil)
+			nodeCounts, victims, err := e.calculatePodsToEvict(nodes, pods, tt.unmanaged, &tt.spec, nil)
+			if err != nil {
+				t.Fatalf("calculatePodsToEvict() error = %v", err)
+			}
+			got := make(map[string]string, len(nodeCounts))
+			for _, nc := range nodeCounts {
+				got[nc.NodeName] = fmt.Sprintf("%d %s", nc.Target, nc.TargetSource)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("targets = %v, want %v", got, tt.want)
+			}
+			if len(victims) != tt.evicted {
+				t.Errorf("evicted %d pods, want %d", len(victims), tt.evicted)
+			}
+		})
+	}
+}
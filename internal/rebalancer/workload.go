@@ -0,0 +1,159 @@
+package rebalancer
+
+import (
+	"context"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	korev1alpha1 "github.com/cxfcxf/pod-rebalancer/api/v1alpha1"
+)
+
+// OrphanWorkloadKind is the workload kind used for pods without a controller
+const OrphanWorkloadKind = "None"
+
+// defaultWorkloadActions apply to workload kinds a request does not set a policy for.
+// Kinds not listed here are evicted.
+var defaultWorkloadActions = map[string]korev1alpha1.WorkloadAction{
+	OrphanWorkloadKind: korev1alpha1.WorkloadActionSkip,
+	"Job":              korev1alpha1.WorkloadActionSkip,
+	"CronJob":          korev1alpha1.WorkloadActionSkip,
+	"StatefulSet":      korev1alpha1.WorkloadActionSerial,
+}
+
+// WorkloadRef identifies the top-level workload that owns a pod
+type WorkloadRef struct {
+	Kind      string
+	Namespace string
+	Name      string
+}
+
+// String returns the workload as kind/namespace/name
+func (w WorkloadRef) String() string {
+	return w.Kind + "/" + w.Namespace + "/" + w.Name
+}
+
+// workloadResolver resolves and caches pod owners for the duration of a single run
+type workloadResolver struct {
+	client client.Client
+	owners map[WorkloadRef]WorkloadRef
+	ready  map[WorkloadRef]bool
+}
+
+func newWorkloadResolver(c client.Client) *workloadResolver {
+	return &workloadResolver{
+		client: c,
+		owners: make(map[WorkloadRef]WorkloadRef),
+		ready:  make(map[WorkloadRef]bool),
+	}
+}
+
+// resolve follows controller owner references from a pod to its top-level workload.
+// ReplicaSets resolve to their Deployment and Jobs to their CronJob. An owner that
+// cannot be found is treated as the top-level workload.
+func (r *workloadResolver) resolve(ctx context.Context, pod *corev1.Pod) (WorkloadRef, error) {
+	ref := metav1.GetControllerOf(pod)
+	if ref == nil {
+		return WorkloadRef{Kind: OrphanWorkloadKind, Namespace: pod.Namespace, Name: pod.Name}, nil
+	}
+	direct := WorkloadRef{Kind: ref.Kind, Namespace: pod.Namespace, Name: ref.Name}
+	if workload, ok := r.owners[direct]; ok {
+		return workload, nil
+	}
+
+	workload := direct
+	var owner client.Object
+	switch ref.Kind {
+	case "ReplicaSet":
+		owner = &appsv1.ReplicaSet{}
+	case "Job":
+		owner = &batchv1.Job{}
+	}
+	if owner != nil {
+		err := r.client.Get(ctx, client.ObjectKey{Namespace: pod.Namespace, Name: ref.Name}, owner)
+		if client.IgnoreNotFound(err) != nil {
+			return WorkloadRef{}, err
+		}
+		if parent := metav1.GetControllerOf(owner); err == nil && parent != nil {
+			workload = WorkloadRef{Kind: parent.Kind, Namespace: pod.Namespace, Name: parent.Name}
+		}
+	}
+
+	r.owners[direct] = workload
+	return workload, nil
+}
+
+// isReady checks if all replicas of a workload are Ready.
+// Kinds without replica status are always considered ready.
+func (r *workloadResolver) isReady(ctx context.Context, workload WorkloadRef) (bool, error) {
+	if ready, ok := r.ready[workload]; ok {
+		return ready, nil
+	}
+
+	key := client.ObjectKey{Namespace: workload.Namespace, Name: workload.Name}
+	ready := true
+	switch workload.Kind {
+	case "StatefulSet":
+		var sts appsv1.StatefulSet
+		if err := r.client.Get(ctx, key, &sts); err != nil {
+			return false, client.IgnoreNotFound(err)
+		}
+		ready = sts.Status.ReadyReplicas >= replicasOrDefault(sts.Spec.Replicas)
+	case "Deployment":
+		var deploy appsv1.Deployment
+		if err := r.client.Get(ctx, key, &deploy); err != nil {
+			return false, client.IgnoreNotFound(err)
+		}
+		ready = deploy.Status.ReadyReplicas >= replicasOrDefault(deploy.Spec.Replicas)
+	case "ReplicaSet":
+		var rs appsv1.ReplicaSet
+		if err := r.client.Get(ctx, key, &rs); err != nil {
+			return false, client.IgnoreNotFound(err)
+		}
+		ready = rs.Status.ReadyReplicas >= replicasOrDefault(rs.Spec.Replicas)
+	}
+
+	r.ready[workload] = ready
+	return ready, nil
+}
+
+// getWorkloadAction returns the action for a workload kind from the request's policies or the defaults
+func getWorkloadAction(kind string, policies []korev1alpha1.WorkloadPolicy) korev1alpha1.WorkloadAction {
+	for _, policy := range policies {
+		if policy.Kind == kind {
+			return policy.Action
+		}
+	}
+	if action, ok := defaultWorkloadActions[kind]; ok {
+		return action
+	}
+	return korev1alpha1.WorkloadActionEvict
+}
+
+// newWorkloadGuard returns a filter for victim selection that allows only one
+// eviction per run for workloads with the Serial action, and none while not all
+// of their replicas are Ready
+func newWorkloadGuard(candidates *candidateSet) func(pod *corev1.Pod) bool {
+	selected := make(map[WorkloadRef]bool)
+	return func(pod *corev1.Pod) bool {
+		workload, ok := candidates.workloads[client.ObjectKeyFromObject(pod)]
+		if !ok || !candidates.serial[workload] {
+			return true
+		}
+		if selected[workload] || candidates.notReady[workload] {
+			return false
+		}
+		selected[workload] = true
+		return true
+	}
+}
+
+func replicasOrDefault(replicas *int32) int32 {
+	if replicas == nil {
+		return 1
+	}
+	return *replicas
+}
@@ -0,0 +1,87 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+
+	"sigs.k8s.io/yaml"
+)
+
+// Output formats
+const (
+	OutputText = "text"
+	OutputJSON = "json"
+	OutputYAML = "yaml"
+)
+
+// Print writes the value in the given format, using printText for text output
+func Print(w io.Writer, format string, value interface{}, printText func(io.Writer) error) error {
+	switch format {
+	case "", OutputText:
+		return printText(w)
+	case OutputJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(value)
+	case OutputYAML:
+		out, err := yaml.Marshal(value)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(out)
+		return err
+	default:
+		return fmt.Errorf("unknown output format %q", format)
+	}
+}
+
+// PrintText writes the result as tables
+func (r *Result) PrintText(w io.Writer) error {
+	fmt.Fprintf(w, "Request: %s\n", r.Request)
+	fmt.Fprintf(w, "Result:  %s\n\n", r.Message)
+	if r.Tripped != "" {
+		fmt.Fprintf(w, "Circuit breaker guard: %s\n\n", r.Tripped)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "NODE\tPODS\tMAX\tTARGET\tSOURCE\tEVICT\tEXPECTED")
+	for _, node := range r.Nodes {
+		maxPods := "-"
+		if node.MaxPods > 0 {
+			maxPods = fmt.Sprint(node.MaxPods)
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%s\t%d\t%s\t%d\t%d\n",
+			node.Name, node.PodCount, maxPods, node.Target, node.TargetSource, node.Evicted, node.Expected)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if len(r.Victims) > 0 {
+		fmt.Fprintln(w, "\nVictims:")
+		tw = tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "  POD\tNODE\tWORKLOAD")
+		for _, victim := range r.Victims {
+			fmt.Fprintf(tw, "  %s\t%s\t%s\n", victim.Pod, victim.Node, victim.Workload)
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+	}
+
+	if len(r.Skipped) > 0 {
+		reasons := make([]string, 0, len(r.Skipped))
+		for reason := range r.Skipped {
+			reasons = append(reasons, reason)
+		}
+		sort.Strings(reasons)
+		fmt.Fprintln(w, "\nSkipped:")
+		for _, reason := range reasons {
+			fmt.Fprintf(w, "  %s: %d\n", reason, r.Skipped[reason])
+		}
+	}
+	return nil
+}
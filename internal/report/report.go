@@ -0,0 +1,107 @@
+// Package report summarizes rebalance plans for people and tools: the debug server,
+// the kubectl plugin and the simulator print the same result.
+package report
+
+import (
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	korev1alpha1 "github.com/cxfcxf/pod-rebalancer/api/v1alpha1"
+	"github.com/cxfcxf/pod-rebalancer/internal/rebalancer"
+)
+
+// Result is the outcome of planning a single rebalance run
+type Result struct {
+	Request string         `json:"request"`
+	Message string         `json:"message"`
+	Tripped string         `json:"tripped,omitempty"`
+	Nodes   []NodeResult   `json:"nodes"`
+	Victims []Victim       `json:"victims"`
+	Skipped map[string]int `json:"skipped,omitempty"`
+}
+
+// NodeResult is one node's distribution before and after the run
+type NodeResult struct {
+	Name         string `json:"name"`
+	PodCount     int    `json:"podCount"`
+	MaxPods      int    `json:"maxPods,omitempty"`
+	Target       int    `json:"target"`
+	TargetSource string `json:"targetSource"`
+	Evicted      int    `json:"evicted"`
+	// Expected is the pod count once the evicted pods' replacements are scheduled
+	Expected int `json:"expected"`
+}
+
+// Victim is a pod the run would evict
+type Victim struct {
+	Pod      string `json:"pod"`
+	Node     string `json:"node"`
+	Workload string `json:"workload"`
+}
+
+// NewResult summarizes a plan for the request, placing the victims' replacements
+func NewResult(req *korev1alpha1.RebalanceRequest, plan *rebalancer.Plan) *Result {
+	result := &Result{
+		Request: req.Namespace + "/" + req.Name,
+		Message: plan.Message,
+		Tripped: plan.Tripped,
+		Nodes:   make([]NodeResult, 0, len(plan.Nodes)),
+		Victims: make([]Victim, 0, len(plan.Victims)),
+	}
+	if len(plan.Victims) > 0 {
+		result.Message = "Would evict pods exceeding limits"
+	}
+
+	evicted := make(map[string]int)
+	for i := range plan.Victims {
+		pod := &plan.Victims[i]
+		evicted[pod.Spec.NodeName]++
+		result.Victims = append(result.Victims, Victim{
+			Pod:      pod.Namespace + "/" + pod.Name,
+			Node:     pod.Spec.NodeName,
+			Workload: plan.Workloads[client.ObjectKeyFromObject(pod)].String(),
+		})
+	}
+
+	for _, nc := range plan.Nodes {
+		node := NodeResult{
+			Name:         nc.NodeName,
+			PodCount:     nc.PodCount,
+			Target:       nc.Target,
+			TargetSource: nc.TargetSource,
+			Evicted:      evicted[nc.NodeName],
+			Expected:     nc.PodCount - evicted[nc.NodeName],
+		}
+		if nc.MaxPods > 0 {
+			node.MaxPods = nc.MaxPods
+		}
+		result.Nodes = append(result.Nodes, node)
+	}
+	placeReplacements(result.Nodes, result.Victims)
+
+	if len(plan.Skipped) > 0 {
+		result.Skipped = make(map[string]int)
+		for _, skipped := range plan.Skipped {
+			result.Skipped[string(skipped.Reason)]++
+		}
+	}
+	return result
+}
+
+// placeReplacements assigns each victim's replacement to the node furthest below its target,
+// other than the node it was evicted from
+func placeReplacements(nodes []NodeResult, victims []Victim) {
+	for _, victim := range victims {
+		best := -1
+		for i := range nodes {
+			if nodes[i].Name == victim.Node && len(nodes) > 1 {
+				continue
+			}
+			if best < 0 || nodes[i].Target-nodes[i].Expected > nodes[best].Target-nodes[best].Expected {
+				best = i
+			}
+		}
+		if best >= 0 {
+			nodes[best].Expected++
+		}
+	}
+}
@@ -0,0 +1,325 @@
+package simulator
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	korev1alpha1 "github.com/cxfcxf/pod-rebalancer/api/v1alpha1"
+	"github.com/cxfcxf/pod-rebalancer/internal/rebalancer"
+)
+
+// ConvergenceOptions configure a multi-round simulation
+type ConvergenceOptions struct {
+	Options
+
+	// MaxRounds bounds the number of rebalance intervals simulated
+	MaxRounds int
+
+	// Scheduler places the replacement of every evicted pod
+	Scheduler Scheduler
+
+	// SchedulerName is reported in the result
+	SchedulerName string
+}
+
+// Round is the outcome of one simulated rebalance interval
+type Round struct {
+	Round int `json:"round"`
+	// Distribution is the managed pod count per node the run started from
+	Distribution  map[string]int `json:"distribution"`
+	Spread        int            `json:"spread"`
+	Evicted       int            `json:"evicted"`
+	Unschedulable int            `json:"unschedulable,omitempty"`
+	Tripped       string         `json:"tripped,omitempty"`
+	// Seconds is the time spent waiting between eviction batches
+	Seconds int    `json:"seconds"`
+	Message string `json:"message"`
+}
+
+// Convergence is the outcome of simulating repeated rebalance intervals
+type Convergence struct {
+	Request        string  `json:"request"`
+	Scheduler      string  `json:"scheduler"`
+	Rounds         []Round `json:"rounds"`
+	Converged      bool    `json:"converged"`
+	EvictingRounds int     `json:"evictingRounds"`
+	TotalEvictions int     `json:"totalEvictions"`
+	// Oscillating is set when a distribution repeats while pods are still being evicted
+	Oscillating bool `json:"oscillating"`
+	Period      int  `json:"period,omitempty"`
+	// Seconds estimates the time to converge from the interval and batch pacing
+	Seconds int `json:"seconds"`
+}
+
+// clusterState is the simulated cluster between rounds
+type clusterState struct {
+	objects []client.Object
+	pods    []*corev1.Pod
+	// createdRound is the round each replacement pod was created in
+	createdRound map[*corev1.Pod]int
+	evictions    []simulatedEviction
+	counter      int
+}
+
+// simulatedEviction is an eviction and the round it happened in
+type simulatedEviction struct {
+	record korev1alpha1.EvictionRecord
+	round  int
+}
+
+// SimulateConvergence runs the request's rebalance repeatedly against the snapshot, scheduling
+// each evicted pod's replacement with the scheduler model, until a run evicts nothing,
+// a distribution repeats, or the round limit is reached
+func SimulateConvergence(ctx context.Context, scheme *runtime.Scheme, snapshot *Snapshot, req *korev1alpha1.RebalanceRequest, opts ConvergenceOptions) (*Convergence, error) {
+	state := newClusterState(snapshot)
+	interval := time.Duration(req.Spec.IntervalSeconds) * time.Second
+	if interval < 30*time.Second {
+		interval = 60 * time.Second
+	}
+	batchSize := int(req.Spec.BatchSize)
+	if batchSize <= 0 {
+		batchSize = 5
+	}
+	batchInterval := int(req.Spec.BatchIntervalSeconds)
+	if batchInterval <= 0 {
+		batchInterval = 30
+	}
+
+	result := &Convergence{Request: req.Namespace + "/" + req.Name, Scheduler: opts.SchedulerName}
+	seen := make(map[string]int)
+	for r := 1; r <= opts.MaxRounds; r++ {
+		// The engine judges history and pod age against the wall clock, so shift the
+		// simulated timeline to end now
+		now := time.Now()
+		state.restamp(req, r, now, interval)
+
+		engine := rebalancer.NewEngine(state.client(scheme))
+		if opts.OptInLabel != "" {
+			engine.OptInLabel = opts.OptInLabel
+		}
+		plan, err := engine.Plan(ctx, req)
+		if err != nil {
+			return nil, fmt.Errorf("round %d: %w", r, err)
+		}
+
+		round := Round{
+			Round:        r,
+			Distribution: make(map[string]int, len(plan.Nodes)),
+			Evicted:      len(plan.Victims),
+			Tripped:      plan.Tripped,
+			Message:      plan.Message,
+		}
+		minPods, maxPods := -1, 0
+		for _, nc := range plan.Nodes {
+			round.Distribution[nc.NodeName] = nc.PodCount
+			if minPods < 0 || nc.PodCount < minPods {
+				minPods = nc.PodCount
+			}
+			if nc.PodCount > maxPods {
+				maxPods = nc.PodCount
+			}
+		}
+		if minPods >= 0 {
+			round.Spread = maxPods - minPods
+		}
+
+		if len(plan.Victims) == 0 {
+			result.Rounds = append(result.Rounds, round)
+			result.Converged = plan.Tripped == ""
+			break
+		}
+
+		signature := distributionSignature(round.Distribution)
+		if previous, ok := seen[signature]; ok {
+			round.Message = fmt.Sprintf("Distribution repeats round %d", previous)
+			result.Rounds = append(result.Rounds, round)
+			result.Oscillating = true
+			result.Period = r - previous
+			break
+		}
+		seen[signature] = r
+
+		// Evict in batches, letting the scheduler place each batch's replacements
+		for i := 0; i < len(plan.Victims); i += batchSize {
+			end := i + batchSize
+			if end > len(plan.Victims) {
+				end = len(plan.Victims)
+			}
+			for j := i; j < end; j++ {
+				victim := &plan.Victims[j]
+				workload := plan.Workloads[client.ObjectKeyFromObject(victim)]
+				if !state.replace(victim, workload, r, opts.Scheduler) {
+					round.Unschedulable++
+				}
+			}
+			if end < len(plan.Victims) {
+				round.Seconds += batchInterval
+			}
+		}
+		round.Message = fmt.Sprintf("Evicted %d pods", round.Evicted)
+
+		result.Rounds = append(result.Rounds, round)
+		result.EvictingRounds++
+		result.TotalEvictions += round.Evicted
+		result.Seconds += round.Seconds + int(interval.Seconds())
+	}
+	return result, nil
+}
+
+func newClusterState(snapshot *Snapshot) *clusterState {
+	state := &clusterState{createdRound: make(map[*corev1.Pod]int)}
+	for _, obj := range append(snapshot.namespaces(), snapshot.Objects...) {
+		if pod, ok := obj.(*corev1.Pod); ok {
+			state.pods = append(state.pods, pod.DeepCopy())
+			continue
+		}
+		state.objects = append(state.objects, obj)
+	}
+	return state
+}
+
+// client builds an in-memory client holding the current state
+func (s *clusterState) client(scheme *runtime.Scheme) client.Client {
+	objects := make([]client.Object, 0, len(s.objects)+len(s.pods))
+	objects = append(objects, s.objects...)
+	for _, pod := range s.pods {
+		objects = append(objects, pod.DeepCopy())
+	}
+	return newClient(scheme, &Snapshot{Objects: objects})
+}
+
+// restamp dates replacements and evictions relative to now, one interval per round
+func (s *clusterState) restamp(req *korev1alpha1.RebalanceRequest, round int, now time.Time, interval time.Duration) {
+	for pod, created := range s.createdRound {
+		pod.CreationTimestamp = metav1.NewTime(now.Add(-time.Duration(round-created) * interval))
+	}
+	req.Status.RecentEvictions = req.Status.RecentEvictions[:0]
+	for _, eviction := range s.evictions {
+		record := eviction.record
+		record.Time = metav1.NewTime(now.Add(-time.Duration(round-eviction.round) * interval))
+		req.Status.RecentEvictions = append(req.Status.RecentEvictions, record)
+	}
+}
+
+// replace removes the victim and schedules its replacement.
+// Returns false if no node fits the replacement, leaving it Pending.
+func (s *clusterState) replace(victim *corev1.Pod, workload rebalancer.WorkloadRef, round int, scheduler Scheduler) bool {
+	key := client.ObjectKeyFromObject(victim)
+	for i, pod := range s.pods {
+		if client.ObjectKeyFromObject(pod) == key {
+			s.pods = append(s.pods[:i], s.pods[i+1:]...)
+			delete(s.createdRound, pod)
+			break
+		}
+	}
+	s.evictions = append(s.evictions, simulatedEviction{
+		record: korev1alpha1.EvictionRecord{
+			Pod:      key.String(),
+			Workload: workload.String(),
+			Node:     victim.Spec.NodeName,
+		},
+		round: round,
+	})
+
+	// Replacements keep everything but their identity and placement, and are younger
+	// than every pod created in earlier rounds
+	s.counter++
+	replacement := victim.DeepCopy()
+	prefix := victim.GenerateName
+	if prefix == "" {
+		prefix = victim.Name + "-"
+	}
+	replacement.Name = fmt.Sprintf("%ssim%d", prefix, s.counter)
+	replacement.UID = ""
+	replacement.Spec.NodeName = ""
+	replacement.Status = corev1.PodStatus{Phase: corev1.PodPending}
+	s.pods = append(s.pods, replacement)
+	s.createdRound[replacement] = round
+
+	feasible := feasibleNodes(replacement, s.nodeStates())
+	if len(feasible) == 0 {
+		replacement.Status.Conditions = []corev1.PodCondition{{
+			Type:   corev1.PodScheduled,
+			Status: corev1.ConditionFalse,
+			Reason: corev1.PodReasonUnschedulable,
+		}}
+		return false
+	}
+	replacement.Spec.NodeName = scheduler.Place(replacement, feasible).Node.Name
+	replacement.Status.Phase = corev1.PodRunning
+	return true
+}
+
+// nodeStates groups the scheduled, non-terminal pods by node
+func (s *clusterState) nodeStates() []*NodeState {
+	byName := make(map[string]*NodeState)
+	var states []*NodeState
+	for _, obj := range s.objects {
+		if node, ok := obj.(*corev1.Node); ok {
+			state := &NodeState{Node: node}
+			byName[node.Name] = state
+			states = append(states, state)
+		}
+	}
+	for _, pod := range s.pods {
+		if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		if state, ok := byName[pod.Spec.NodeName]; ok {
+			state.Pods = append(state.Pods, pod)
+		}
+	}
+	return states
+}
+
+// distributionSignature identifies a distribution for oscillation detection
+func distributionSignature(distribution map[string]int) string {
+	names := make([]string, 0, len(distribution))
+	for name := range distribution {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var b strings.Builder
+	for _, name := range names {
+		fmt.Fprintf(&b, "%s=%d,", name, distribution[name])
+	}
+	return b.String()
+}
+
+// PrintText writes one row per round followed by a summary
+func (c *Convergence) PrintText(w io.Writer) error {
+	fmt.Fprintf(w, "Request:   %s\n", c.Request)
+	fmt.Fprintf(w, "Scheduler: %s\n\n", c.Scheduler)
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "ROUND\tEVICTED\tUNSCHEDULABLE\tSPREAD\tDISTRIBUTION\tRESULT")
+	for _, round := range c.Rounds {
+		fmt.Fprintf(tw, "%d\t%d\t%d\t%d\t%s\t%s\n", round.Round, round.Evicted, round.Unschedulable,
+			round.Spread, strings.TrimSuffix(distributionSignature(round.Distribution), ","), round.Message)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(w)
+	switch {
+	case c.Converged:
+		fmt.Fprintf(w, "Converged after %d evicting rounds and %d evictions (about %s)\n",
+			c.EvictingRounds, c.TotalEvictions, time.Duration(c.Seconds)*time.Second)
+	case c.Oscillating:
+		fmt.Fprintf(w, "Oscillating with a period of %d rounds after %d evictions\n", c.Period, c.TotalEvictions)
+	default:
+		fmt.Fprintf(w, "Did not converge within %d rounds (%d evictions)\n", len(c.Rounds), c.TotalEvictions)
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package simulator
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestSimulateConvergence(t *testing.T) {
+	// 12 pods on a=8, b=4, c=0 with a balanced target of 5 per node
+	tests := []struct {
+		name          string
+		scheduler     string
+		seed          int64
+		distributions []string
+		evictions     int
+		converged     bool
+		period        int
+	}{
+		{
+			name:          "least allocated fills the empty node",
+			scheduler:     SchedulerLeastAllocated,
+			distributions: []string{"a=8,b=4,c=0,", "a=5,b=4,c=3,"},
+			evictions:     3,
+			converged:     true,
+		},
+		{
+			name:      "seeded random converges in three evicting rounds",
+			scheduler: SchedulerRandom,
+			seed:      7,
+			distributions: []string{
+				"a=8,b=4,c=0,", "a=7,b=4,c=1,", "a=6,b=4,c=2,", "a=5,b=4,c=3,",
+			},
+			evictions: 6,
+			converged: true,
+		},
+		{
+			name:          "most allocated packs replacements back onto the fullest node",
+			scheduler:     SchedulerMostAllocated,
+			distributions: []string{"a=8,b=4,c=0,", "a=8,b=4,c=0,"},
+			evictions:     3,
+			period:        1,
+		},
+		{
+			name:          "seeded random oscillates",
+			scheduler:     SchedulerRandom,
+			seed:          3,
+			distributions: []string{"a=8,b=4,c=0,", "a=6,b=5,c=1,", "a=6,b=5,c=1,"},
+			evictions:     4,
+			period:        1,
+		},
+	}
+	snapshot := loadTestSnapshot(t)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			simulate := func() *Convergence {
+				req, err := snapshot.Request("pod-rebalancer")
+				if err != nil {
+					t.Fatal(err)
+				}
+				scheduler, err := NewScheduler(tt.scheduler, tt.seed)
+				if err != nil {
+					t.Fatal(err)
+				}
+				convergence, err := SimulateConvergence(context.Background(), NewScheme(), snapshot, req, ConvergenceOptions{
+					MaxRounds: 10,
+					Scheduler: scheduler,
+				})
+				if err != nil {
+					t.Fatalf("SimulateConvergence() error = %v", err)
+				}
+				return convergence
+			}
+			got := simulate()
+
+			var distributions []string
+			for _, round := range got.Rounds {
+				distributions = append(distributions, distributionSignature(round.Distribution))
+			}
+			if !reflect.DeepEqual(distributions, tt.distributions) {
+				t.Errorf("distributions = %v, want %v", distributions, tt.distributions)
+			}
+			if got.TotalEvictions != tt.evictions {
+				t.Errorf("TotalEvictions = %d, want %d", got.TotalEvictions, tt.evictions)
+			}
+			if got.Converged != tt.converged {
+				t.Errorf("Converged = %v, want %v", got.Converged, tt.converged)
+			}
+			if got.Oscillating != (tt.period > 0) || got.Period != tt.period {
+				t.Errorf("Oscillating = %v with period %d, want period %d", got.Oscillating, got.Period, tt.period)
+			}
+			// The same seed replays the same rounds
+			if again := simulate(); !reflect.DeepEqual(again.Rounds, got.Rounds) {
+				t.Errorf("rerun rounds = %+v, want %+v", again.Rounds, got.Rounds)
+			}
+		})
+	}
+}
@@ -0,0 +1,185 @@
+package simulator
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/labels"
+
+	"github.com/cxfcxf/pod-rebalancer/internal/rebalancer"
+)
+
+// Scheduler models names
+const (
+	SchedulerLeastAllocated = "least-allocated"
+	SchedulerMostAllocated  = "most-allocated"
+	SchedulerRandom         = "random"
+)
+
+// Schedulers lists the available scheduler models
+var Schedulers = []string{SchedulerLeastAllocated, SchedulerMostAllocated, SchedulerRandom}
+
+// NodeState is a node and the pods currently running on it
+type NodeState struct {
+	Node *corev1.Node
+	Pods []*corev1.Pod
+}
+
+// Scheduler models how the cluster scheduler places replacement pods
+type Scheduler interface {
+	// Place picks a node for the pod from the feasible nodes, which is never empty
+	Place(pod *corev1.Pod, feasible []*NodeState) *NodeState
+}
+
+// NewScheduler returns the named scheduler model. seed drives the random model.
+func NewScheduler(name string, seed int64) (Scheduler, error) {
+	switch name {
+	case "", SchedulerLeastAllocated:
+		return scoringScheduler{prefer: func(a, b float64) bool { return a < b }}, nil
+	case SchedulerMostAllocated:
+		return scoringScheduler{prefer: func(a, b float64) bool { return a > b }}, nil
+	case SchedulerRandom:
+		return &randomScheduler{rand: rand.New(rand.NewSource(seed))}, nil
+	default:
+		return nil, fmt.Errorf("unknown scheduler %q, expected one of %v", name, Schedulers)
+	}
+}
+
+// scoringScheduler places pods on the node with the preferred allocation, like the
+// NodeResourcesFit LeastAllocated and MostAllocated scoring strategies
+type scoringScheduler struct {
+	prefer func(a, b float64) bool
+}
+
+func (s scoringScheduler) Place(pod *corev1.Pod, feasible []*NodeState) *NodeState {
+	best, bestScore := feasible[0], allocation(feasible[0], pod)
+	for _, state := range feasible[1:] {
+		if score := allocation(state, pod); s.prefer(score, bestScore) {
+			best, bestScore = state, score
+		}
+	}
+	return best
+}
+
+// randomScheduler places pods on a uniformly random feasible node
+type randomScheduler struct {
+	rand *rand.Rand
+}
+
+func (s *randomScheduler) Place(_ *corev1.Pod, feasible []*NodeState) *NodeState {
+	return feasible[s.rand.Intn(len(feasible))]
+}
+
+// feasibleNodes returns the nodes the pod could be scheduled to, in name order.
+// It checks readiness, cordons, taints, the pod's nodeSelector, the node's pod limit and
+// CPU and memory requests; affinity and topology spread constraints are not modelled.
+func feasibleNodes(pod *corev1.Pod, nodes []*NodeState) []*NodeState {
+	var feasible []*NodeState
+	for _, state := range nodes {
+		if fits(pod, state) {
+			feasible = append(feasible, state)
+		}
+	}
+	sort.Slice(feasible, func(i, j int) bool {
+		return feasible[i].Node.Name < feasible[j].Node.Name
+	})
+	return feasible
+}
+
+func fits(pod *corev1.Pod, state *NodeState) bool {
+	node := state.Node
+	if node.Spec.Unschedulable || !rebalancer.IsNodeReady(node) {
+		return false
+	}
+	if !labels.SelectorFromSet(pod.Spec.NodeSelector).Matches(labels.Set(node.Labels)) {
+		return false
+	}
+	if !rebalancer.ToleratesNodeTaints(node, pod.Spec.Tolerations) {
+		return false
+	}
+
+	if maxPods, ok := node.Status.Allocatable[corev1.ResourcePods]; ok && int64(len(state.Pods)) >= maxPods.Value() {
+		return false
+	}
+	for _, name := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+		allocatable, ok := node.Status.Allocatable[name]
+		if !ok {
+			continue
+		}
+		used := requested(state, name)
+		used.Add(podRequest(pod, name))
+		if used.Cmp(allocatable) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// allocation is the node's average CPU and memory request share if the pod were placed on it,
+// falling back to its pod count share when the node reports neither. Like the scheduler's
+// scoring, containers without requests count as 100m CPU and 200Mi memory.
+func allocation(state *NodeState, pod *corev1.Pod) float64 {
+	var share float64
+	var resources int
+	for _, name := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+		allocatable, ok := state.Node.Status.Allocatable[name]
+		if !ok || allocatable.IsZero() {
+			continue
+		}
+		used := scoringRequest(pod, name)
+		for _, p := range state.Pods {
+			used.Add(scoringRequest(p, name))
+		}
+		share += float64(used.MilliValue()) / float64(allocatable.MilliValue())
+		resources++
+	}
+	if resources > 0 {
+		return share / float64(resources)
+	}
+	if maxPods, ok := state.Node.Status.Allocatable[corev1.ResourcePods]; ok && !maxPods.IsZero() {
+		return float64(len(state.Pods)+1) / float64(maxPods.Value())
+	}
+	return float64(len(state.Pods) + 1)
+}
+
+// requested sums the requests of the pods on the node
+func requested(state *NodeState, name corev1.ResourceName) resource.Quantity {
+	var total resource.Quantity
+	for _, pod := range state.Pods {
+		total.Add(podRequest(pod, name))
+	}
+	return total
+}
+
+// podRequest sums the container requests of a pod for one resource
+func podRequest(pod *corev1.Pod, name corev1.ResourceName) resource.Quantity {
+	var total resource.Quantity
+	for _, c := range pod.Spec.Containers {
+		if q, ok := c.Resources.Requests[name]; ok {
+			total.Add(q)
+		}
+	}
+	return total
+}
+
+// scoringRequest is podRequest with the scheduler's defaults for containers without requests
+func scoringRequest(pod *corev1.Pod, name corev1.ResourceName) resource.Quantity {
+	var total resource.Quantity
+	for _, c := range pod.Spec.Containers {
+		if q, ok := c.Resources.Requests[name]; ok && !q.IsZero() {
+			total.Add(q)
+		} else {
+			total.Add(defaultRequests[name])
+		}
+	}
+	return total
+}
+
+// defaultRequests are the requests the scheduler assumes for scoring when a container sets none
+var defaultRequests = map[corev1.ResourceName]resource.Quantity{
+	corev1.ResourceCPU:    resource.MustParse("100m"),
+	corev1.ResourceMemory: resource.MustParse("200Mi"),
+}
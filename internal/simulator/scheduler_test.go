@@ -0,0 +1,130 @@
+package simulator
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// nodeState returns a ready node with 4 CPUs, 16Gi and room for 10 pods, running pods
+// that each request 1 CPU and 1Gi
+func nodeState(name string, pods int) *NodeState {
+	state := &NodeState{Node: &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{"pool": "web"}},
+		Status: corev1.NodeStatus{
+			Allocatable: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("4"),
+				corev1.ResourceMemory: resource.MustParse("16Gi"),
+				corev1.ResourcePods:   resource.MustParse("10"),
+			},
+			Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionTrue}},
+		},
+	}}
+	for i := 0; i < pods; i++ {
+		state.Pods = append(state.Pods, requestingPod("1", "1Gi"))
+	}
+	return state
+}
+
+func requestingPod(cpu, memory string) *corev1.Pod {
+	return &corev1.Pod{Spec: corev1.PodSpec{Containers: []corev1.Container{{
+		Name: "app",
+		Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse(cpu),
+			corev1.ResourceMemory: resource.MustParse(memory),
+		}},
+	}}}}
+}
+
+func TestScoringSchedulers(t *testing.T) {
+	tests := []struct {
+		scheduler string
+		want      string
+	}{
+		{scheduler: SchedulerLeastAllocated, want: "empty"},
+		{scheduler: SchedulerMostAllocated, want: "busy"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.scheduler, func(t *testing.T) {
+			scheduler, err := NewScheduler(tt.scheduler, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			feasible := []*NodeState{nodeState("half", 2), nodeState("busy", 3), nodeState("empty", 0)}
+			if got := scheduler.Place(requestingPod("1", "1Gi"), feasible).Node.Name; got != tt.want {
+				t.Errorf("Place() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRandomSchedulerIsSeeded(t *testing.T) {
+	feasible := []*NodeState{nodeState("a", 0), nodeState("b", 0), nodeState("c", 0)}
+	place := func(seed int64) []string {
+		scheduler, err := NewScheduler(SchedulerRandom, seed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var names []string
+		for i := 0; i < 20; i++ {
+			names = append(names, scheduler.Place(requestingPod("1", "1Gi"), feasible).Node.Name)
+		}
+		return names
+	}
+	first, second := place(7), place(7)
+	used := make(map[string]bool)
+	for i := range first {
+		if first[i] != second[i] {
+			t.Fatalf("seed 7 placed %v, then %v", first, second)
+		}
+		used[first[i]] = true
+	}
+	if len(used) != 3 {
+		t.Errorf("20 placements used nodes %v, want all 3", used)
+	}
+}
+
+func TestNewSchedulerUnknown(t *testing.T) {
+	if _, err := NewScheduler("spread", 0); err == nil {
+		t.Error("NewScheduler(\"spread\") succeeded, want an error")
+	}
+}
+
+func TestFeasibleNodes(t *testing.T) {
+	cordoned := nodeState("cordoned", 0)
+	cordoned.Node.Spec.Unschedulable = true
+	notReady := nodeState("not-ready", 0)
+	notReady.Node.Status.Conditions[0].Status = corev1.ConditionFalse
+	tainted := nodeState("tainted", 0)
+	tainted.Node.Spec.Taints = []corev1.Taint{{Key: "gpu", Effect: corev1.TaintEffectNoSchedule}}
+	otherPool := nodeState("other-pool", 0)
+	otherPool.Node.Labels["pool"] = "batch"
+	nodes := []*NodeState{
+		nodeState("fits", 3),
+		nodeState("cpu-full", 4),
+		cordoned, notReady, tainted, otherPool,
+		nodeState("b-fits", 0),
+	}
+	pod := requestingPod("1", "1Gi")
+	pod.Spec.NodeSelector = map[string]string{"pool": "web"}
+
+	feasible := feasibleNodes(pod, nodes)
+	var names []string
+	for _, state := range feasible {
+		names = append(names, state.Node.Name)
+	}
+	if len(names) != 2 || names[0] != "b-fits" || names[1] != "fits" {
+		t.Errorf("feasibleNodes() = %v, want [b-fits fits] in name order", names)
+	}
+
+	// The pod limit applies when requests still fit
+	full := nodeState("pods-full", 0)
+	for i := 0; i < 10; i++ {
+		full.Pods = append(full.Pods, requestingPod("0", "0"))
+	}
+	if got := feasibleNodes(requestingPod("1", "1Gi"), []*NodeState{full}); len(got) != 0 {
+		t.Error("node at its pod limit is feasible")
+	}
+}
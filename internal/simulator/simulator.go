@@ -0,0 +1,44 @@
+package simulator
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	korev1alpha1 "github.com/cxfcxf/pod-rebalancer/api/v1alpha1"
+	"github.com/cxfcxf/pod-rebalancer/internal/rebalancer"
+	"github.com/cxfcxf/pod-rebalancer/internal/report"
+)
+
+// Options configure a simulation
+type Options struct {
+	// OptInLabel is the manager's opt-in label. Defaults to rebalancer.RebalanceEnabledLabel.
+	OptInLabel string
+}
+
+// Simulate plans one rebalance run for the request against the snapshot, without a cluster
+func Simulate(ctx context.Context, scheme *runtime.Scheme, snapshot *Snapshot, req *korev1alpha1.RebalanceRequest, opts Options) (*report.Result, error) {
+	engine := rebalancer.NewEngine(newClient(scheme, snapshot))
+	if opts.OptInLabel != "" {
+		engine.OptInLabel = opts.OptInLabel
+	}
+
+	plan, err := engine.Plan(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return report.NewResult(req, plan), nil
+}
+
+// newClient builds an in-memory client holding the snapshot, indexed like the manager's cache
+func newClient(scheme *runtime.Scheme, snapshot *Snapshot) client.Client {
+	objects := append(snapshot.namespaces(), snapshot.Objects...)
+	return fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(objects...).
+		WithIndex(&corev1.Pod{}, rebalancer.PodNodeNameField, rebalancer.PodNodeName).
+		Build()
+}
@@ -0,0 +1,75 @@
+package simulator
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cxfcxf/pod-rebalancer/internal/report"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// loadTestSnapshot loads the checked-in cluster dump
+func loadTestSnapshot(t *testing.T) *Snapshot {
+	t.Helper()
+	snapshot, err := LoadSnapshot(NewScheme(),
+		filepath.Join("testdata", "nodes.yaml"),
+		filepath.Join("testdata", "pods.yaml"),
+		filepath.Join("testdata", "request.yaml"),
+	)
+	if err != nil {
+		t.Fatalf("LoadSnapshot() error = %v", err)
+	}
+	return snapshot
+}
+
+// checkGolden compares the output with the golden file, or rewrites it with -update
+func checkGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *update {
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("output differs from %s:\n%s\nwant:\n%s", path, got, want)
+	}
+}
+
+func TestSimulateGolden(t *testing.T) {
+	// 12 pods on a=8, b=4, c=0: both requests move a's 3 newest pods
+	tests := []struct {
+		request string
+		golden  string
+	}{
+		{request: "pod-rebalancer", golden: "pod-rebalancer.golden"},
+		{request: "capped", golden: "capped.golden"},
+	}
+	snapshot := loadTestSnapshot(t)
+	for _, tt := range tests {
+		t.Run(tt.request, func(t *testing.T) {
+			req, err := snapshot.Request(tt.request)
+			if err != nil {
+				t.Fatal(err)
+			}
+			result, err := Simulate(context.Background(), NewScheme(), snapshot, req, Options{})
+			if err != nil {
+				t.Fatalf("Simulate() error = %v", err)
+			}
+			var out bytes.Buffer
+			if err := report.Print(&out, report.OutputText, result, result.PrintText); err != nil {
+				t.Fatal(err)
+			}
+			checkGolden(t, tt.golden, out.Bytes())
+		})
+	}
+}
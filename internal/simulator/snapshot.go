@@ -0,0 +1,163 @@
+package simulator
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	korev1alpha1 "github.com/cxfcxf/pod-rebalancer/api/v1alpha1"
+)
+
+// Snapshot holds cluster objects and RebalanceRequests loaded from dumps
+type Snapshot struct {
+	Objects  []client.Object
+	Requests []korev1alpha1.RebalanceRequest
+
+	// Ignored lists the kinds found in the dumps that the simulator does not know
+	Ignored []string
+}
+
+// NewScheme returns a scheme with the Kubernetes built-in types and RebalanceRequests
+func NewScheme() *runtime.Scheme {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(korev1alpha1.AddToScheme(scheme))
+	return scheme
+}
+
+// LoadSnapshot reads YAML or JSON files holding objects, multi-document YAML or Lists,
+// such as the output of kubectl get -o yaml. A path of "-" reads standard input.
+func LoadSnapshot(scheme *runtime.Scheme, paths ...string) (*Snapshot, error) {
+	snapshot := &Snapshot{}
+	ignored := make(map[string]bool)
+	for _, path := range paths {
+		if err := snapshot.load(scheme, path, ignored); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	for kind := range ignored {
+		snapshot.Ignored = append(snapshot.Ignored, kind)
+	}
+	sort.Strings(snapshot.Ignored)
+	return snapshot, nil
+}
+
+// load decodes every document in one file
+func (s *Snapshot) load(scheme *runtime.Scheme, path string, ignored map[string]bool) error {
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	decoder := yaml.NewYAMLOrJSONDecoder(r, 4096)
+	for {
+		var raw map[string]interface{}
+		if err := decoder.Decode(&raw); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+		if len(raw) == 0 {
+			continue
+		}
+
+		obj := &unstructured.Unstructured{Object: raw}
+		if !obj.IsList() {
+			if err := s.add(scheme, obj, ignored); err != nil {
+				return err
+			}
+			continue
+		}
+		list, err := obj.ToList()
+		if err != nil {
+			return err
+		}
+		for i := range list.Items {
+			if err := s.add(scheme, &list.Items[i], ignored); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// add converts an object to its typed form and records it
+func (s *Snapshot) add(scheme *runtime.Scheme, obj *unstructured.Unstructured, ignored map[string]bool) error {
+	gvk := obj.GroupVersionKind()
+	typed, err := scheme.New(gvk)
+	if err != nil {
+		ignored[gvk.Kind] = true
+		return nil
+	}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, typed); err != nil {
+		return fmt.Errorf("invalid %s %s: %w", gvk.Kind, obj.GetName(), err)
+	}
+
+	if req, ok := typed.(*korev1alpha1.RebalanceRequest); ok {
+		s.Requests = append(s.Requests, *req)
+		return nil
+	}
+	clientObj, ok := typed.(client.Object)
+	if !ok {
+		ignored[gvk.Kind] = true
+		return nil
+	}
+	// Dumped resource versions mean nothing to the in-memory client
+	clientObj.SetResourceVersion("")
+	s.Objects = append(s.Objects, clientObj)
+	return nil
+}
+
+// Request returns the named RebalanceRequest, or the only one if name is empty
+func (s *Snapshot) Request(name string) (*korev1alpha1.RebalanceRequest, error) {
+	if name == "" {
+		if len(s.Requests) != 1 {
+			return nil, fmt.Errorf("snapshot has %d RebalanceRequests, choose one by name", len(s.Requests))
+		}
+		return s.Requests[0].DeepCopy(), nil
+	}
+	for i := range s.Requests {
+		if s.Requests[i].Name == name {
+			return s.Requests[i].DeepCopy(), nil
+		}
+	}
+	return nil, fmt.Errorf("RebalanceRequest %q not found in snapshot", name)
+}
+
+// namespaces returns Namespace objects for pods whose namespace is not part of the snapshot,
+// since a pod dump rarely includes its namespaces
+func (s *Snapshot) namespaces() []client.Object {
+	known := make(map[string]bool)
+	for _, obj := range s.Objects {
+		if _, ok := obj.(*corev1.Namespace); ok {
+			known[obj.GetName()] = true
+		}
+	}
+
+	var missing []client.Object
+	for _, obj := range s.Objects {
+		if _, ok := obj.(*corev1.Pod); !ok || known[obj.GetNamespace()] {
+			continue
+		}
+		known[obj.GetNamespace()] = true
+		ns := &corev1.Namespace{}
+		ns.Name = obj.GetNamespace()
+		missing = append(missing, ns)
+	}
+	return missing
+}
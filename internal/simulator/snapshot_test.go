@@ -0,0 +1,78 @@
+package simulator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestLoadSnapshotListOutput(t *testing.T) {
+	snapshot := loadTestSnapshot(t)
+
+	nodes, pods := 0, 0
+	for _, obj := range snapshot.Objects {
+		switch obj.(type) {
+		case *corev1.Node:
+			nodes++
+		case *corev1.Pod:
+			pods++
+		}
+		if obj.GetResourceVersion() != "" {
+			t.Errorf("%s kept resource version %q", obj.GetName(), obj.GetResourceVersion())
+		}
+	}
+	if nodes != 3 || pods != 12 {
+		t.Errorf("loaded %d nodes and %d pods, want 3 and 12", nodes, pods)
+	}
+	if len(snapshot.Requests) != 2 {
+		t.Errorf("loaded %d requests, want 2", len(snapshot.Requests))
+	}
+	if missing := snapshot.namespaces(); len(missing) != 1 || missing[0].GetName() != "shop" {
+		t.Errorf("namespaces() = %v, want the shop namespace", missing)
+	}
+
+	if _, err := snapshot.Request(""); err == nil {
+		t.Error("Request(\"\") with 2 requests succeeded, want an error")
+	}
+	if _, err := snapshot.Request("missing"); err == nil {
+		t.Error("Request(\"missing\") succeeded, want an error")
+	}
+	req, err := snapshot.Request("capped")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req.Spec.NodeTargets[0].MaxPodsPerNode != 5 {
+		t.Errorf("capped maxPodsPerNode = %d, want 5", req.Spec.NodeTargets[0].MaxPodsPerNode)
+	}
+}
+
+func TestLoadSnapshotIgnoresUnknownKinds(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mixed.yaml")
+	dump := `apiVersion: v1
+kind: List
+items:
+- apiVersion: v1
+  kind: Node
+  metadata:
+    name: a
+- apiVersion: example.com/v1
+  kind: Widget
+  metadata:
+    name: w
+`
+	if err := os.WriteFile(path, []byte(dump), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	snapshot, err := LoadSnapshot(NewScheme(), path)
+	if err != nil {
+		t.Fatalf("LoadSnapshot() error = %v", err)
+	}
+	if len(snapshot.Objects) != 1 {
+		t.Errorf("loaded %d objects, want the node only", len(snapshot.Objects))
+	}
+	if len(snapshot.Ignored) != 1 || snapshot.Ignored[0] != "Widget" {
+		t.Errorf("Ignored = %v, want [Widget]", snapshot.Ignored)
+	}
+}
@@ -0,0 +1,132 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	// ExporterNone disables tracing
+	ExporterNone = "none"
+	// ExporterOTLP sends spans to an OTLP gRPC collector
+	ExporterOTLP = "otlp"
+	// ExporterStdout writes spans as JSON, for testing without a collector
+	ExporterStdout = "stdout"
+)
+
+// Span attributes shared by the controller and the engine
+const (
+	// RequestKey is the namespace/name of the RebalanceRequest
+	RequestKey = attribute.Key("rebalancer.request")
+	// ResultKey is the outcome of the traced operation
+	ResultKey = attribute.Key("rebalancer.result")
+	// BatchKey is the 1-based index of an eviction batch
+	BatchKey = attribute.Key("rebalancer.batch")
+)
+
+// Options configures the exported traces
+type Options struct {
+	// Exporter is ExporterNone, ExporterOTLP or ExporterStdout
+	Exporter string
+
+	// Endpoint is the collector's host:port. Empty uses OTEL_EXPORTER_OTLP_ENDPOINT or localhost:4317.
+	Endpoint string
+
+	// Insecure disables TLS to the collector
+	Insecure bool
+
+	// SampleRatio is the fraction of traces sampled, from 0 to 1
+	SampleRatio float64
+
+	// Output receives the spans of ExporterStdout
+	Output io.Writer
+}
+
+// Setup installs the global tracer provider described by opts.
+// Returns a function that flushes and stops it; with ExporterNone spans are discarded and the function does nothing.
+func Setup(ctx context.Context, serviceName string, opts Options) (func(context.Context) error, error) {
+	var exporter sdktrace.SpanExporter
+	var err error
+	switch opts.Exporter {
+	case "", ExporterNone:
+		return func(context.Context) error { return nil }, nil
+	case ExporterOTLP:
+		options := []otlptracegrpc.Option{}
+		if opts.Endpoint != "" {
+			options = append(options, otlptracegrpc.WithEndpoint(opts.Endpoint))
+		}
+		if opts.Insecure {
+			options = append(options, otlptracegrpc.WithInsecure())
+		}
+		exporter, err = otlptracegrpc.New(ctx, options...)
+	case ExporterStdout:
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(opts.Output))
+	default:
+		return nil, fmt.Errorf("unknown trace exporter %q, expected %s, %s or %s", opts.Exporter, ExporterNone, ExporterOTLP, ExporterStdout)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s trace exporter: %w", opts.Exporter, err)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(semconv.ServiceName(serviceName)))
+	if err != nil {
+		return nil, err
+	}
+
+	// Spans written locally are exported as they end so nothing is lost on exit;
+	// spans sent to a collector are batched
+	processor := sdktrace.NewBatchSpanProcessor(exporter)
+	if opts.Exporter == ExporterStdout {
+		processor = sdktrace.NewSimpleSpanProcessor(exporter)
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithSpanProcessor(processor),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(opts.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	return provider.Shutdown, nil
+}
+
+// Request returns the attribute naming a RebalanceRequest
+func Request(key types.NamespacedName) attribute.KeyValue {
+	return RequestKey.String(key.String())
+}
+
+// Pod returns the attributes naming a pod and the node it runs on
+func Pod(pod *corev1.Pod) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		semconv.K8SNamespaceName(pod.Namespace),
+		semconv.K8SPodName(pod.Name),
+		semconv.K8SNodeName(pod.Spec.NodeName),
+	}
+}
+
+// Result returns the attribute describing an operation's outcome
+func Result(result string) attribute.KeyValue {
+	return ResultKey.String(result)
+}
+
+// End records err, if any, as the span's status and ends it
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}